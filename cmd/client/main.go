@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -13,6 +14,7 @@ import (
 	"github.com/pulinau/demo-temporal-order-processor/internal/temporal"
 
 	"go.temporal.io/sdk/client"
+	sdktemporal "go.temporal.io/sdk/temporal"
 )
 
 func main() {
@@ -20,18 +22,55 @@ func main() {
 
 	configPath := flag.String("config", "", "path to config file")
 	orderPayload := flag.String("order", "", "json order payload")
+	workflowID := flag.String("workflow-id", "", "workflow ID of an existing order, required with -update, -restock or -review")
+	update := flag.String("update", "", fmt.Sprintf("update to send to an existing order: %s, %s, %s, %s, %s, %s or %s",
+		temporal.PickOrderUpdate, temporal.ShipOrderUpdate, temporal.ShipItemsUpdate, temporal.OrderDeliveredUpdate,
+		temporal.CancelOrderUpdate, temporal.RequestReturnUpdate, temporal.ReceiveReturnUpdate))
+	actor := flag.String("actor", "", "who is sending the update or review, required with -review or -update="+temporal.CancelOrderUpdate)
+	reason := flag.String("reason", "", "why the update or review is being sent")
+	trackingNumber := flag.String("tracking-number", "", "tracking number of the parcel, required with -update="+temporal.ShipItemsUpdate)
+	items := flag.String("items", "", fmt.Sprintf("json array of {product_id, quantity} being shipped or returned, required with -update=%s or -update=%s",
+		temporal.ShipItemsUpdate, temporal.RequestReturnUpdate))
+	var sla temporal.SLA
+	flag.DurationVar(&sla.PickWithin, "pick-within", 0, "escalate a new order that is not picked within this duration")
+	flag.DurationVar(&sla.ShipWithin, "ship-within", 0, "escalate a new order that is not shipped within this duration of being picked")
+	flag.DurationVar(&sla.DeliverWithin, "deliver-within", 0, "escalate a new order that is not delivered within this duration of being shipped")
+	flag.BoolVar(&sla.AutoCancel, "auto-cancel", false, "cancel a new order that breaches its pick or ship deadline")
+	returnWindow := flag.Duration("return-window", 0, "accept returns for a new order for this duration after delivery")
+	var backorder temporal.BackorderOptions
+	flag.Func("backorder", fmt.Sprintf("what to do if a new order has items out of stock: %s (default), %s or %s",
+		temporal.BackorderReject, temporal.BackorderWait, temporal.BackorderSplit), func(policy string) error {
+		switch p := temporal.BackorderPolicy(policy); p {
+		case temporal.BackorderReject, temporal.BackorderWait, temporal.BackorderSplit:
+			backorder.Policy = p
+			return nil
+		default:
+			return fmt.Errorf("unknown backorder policy %q", policy)
+		}
+	})
+	flag.DurationVar(&backorder.WaitFor, "backorder-wait", 0, "how long a backordered order waits for stock before it fails")
+	flag.DurationVar(&backorder.PollInterval, "backorder-poll", 0, "how often a backordered order checks inventory again")
+	restock := flag.Bool("restock", false, "tell an existing backordered order that stock has arrived")
+	review := flag.String("review", "", "approve or reject an existing order held for fraud review")
+	fraudReviewTimeout := flag.Duration("review-timeout", 0, "reject a new order held for fraud review if it is not reviewed within this duration")
 	flag.Parse()
 
 	if *configPath == "" {
 		*configPath = "./config/client/local/config.yaml"
 	}
 
-	if *orderPayload == "" {
+	if *update == "" && !*restock && *review == "" && *orderPayload == "" {
 		slog.Error("json order payload is required")
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	if (*update != "" || *restock || *review != "") && *workflowID == "" {
+		slog.Error("workflow ID is required to send an update, restock or review signal")
+		flag.Usage()
+		os.Exit(1)
+	}
+
 	cfg, err := config.LoadConfig(*configPath)
 	if err != nil {
 		slog.Error("Unable to load config", "error", err)
@@ -48,33 +87,169 @@ func main() {
 	}
 	defer c.Close()
 
+	if *restock {
+		if err := c.SignalWorkflow(context.Background(), *workflowID, "", temporal.RestockSignal, nil); err != nil {
+			slog.Error("Unable to signal restock", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Restock signalled", "workflowID", *workflowID)
+		return
+	}
+
+	if *review != "" {
+		reviewOrder(c, *workflowID, *review, temporal.TransitionRequest{Actor: *actor, Reason: *reason})
+		return
+	}
+
+	switch *update {
+	case temporal.ShipItemsUpdate:
+		req := temporal.ShipmentRequest{Actor: *actor, Reason: *reason, TrackingNumber: *trackingNumber}
+		if err := json.Unmarshal([]byte(*items), &req.Items); err != nil {
+			slog.Error("Unable to unmarshall shipment items", "error", err)
+			os.Exit(2)
+		}
+		updateOrder(c, *workflowID, *update, req)
+		return
+
+	case temporal.RequestReturnUpdate:
+		req := temporal.ReturnRequest{Actor: *actor, Reason: *reason}
+		if err := json.Unmarshal([]byte(*items), &req.Items); err != nil {
+			slog.Error("Unable to unmarshall returned items", "error", err)
+			os.Exit(2)
+		}
+		updateOrder(c, *workflowID, *update, req)
+		return
+	}
+
+	if *update != "" {
+		updateOrder(c, *workflowID, *update, temporal.TransitionRequest{Actor: *actor, Reason: *reason})
+		return
+	}
+
+	startOrder(c, cfg.Temporal.TaskQueueName, *orderPayload, temporal.Params{
+		SLA:                sla,
+		ReturnWindow:       *returnWindow,
+		Backorder:          backorder,
+		FraudReviewTimeout: *fraudReviewTimeout,
+	})
+}
+
+// startOrder starts a new order workflow with params and waits for it to
+// complete.
+func startOrder(c client.Client, taskQueue, orderPayload string, params temporal.Params) {
 	workflowID := "order-" + uuid.New().String()
 
 	options := client.StartWorkflowOptions{
 		ID:        workflowID,
-		TaskQueue: cfg.Temporal.TaskQueueName,
+		TaskQueue: taskQueue,
 	}
 
-	var order temporal.Order
-	err = json.Unmarshal([]byte(*orderPayload), &order)
+	err := json.Unmarshal([]byte(orderPayload), &params.Order)
 	if err != nil {
 		slog.Error("Unable to unmarshall payload into order struct", "error", err)
 		os.Exit(2)
 	}
 
-	we, err := c.ExecuteWorkflow(context.Background(), options, temporal.ProccessOrder, temporal.Params{
-		Order: order,
-	})
+	we, err := c.ExecuteWorkflow(context.Background(), options, temporal.ProccessOrder, params)
 	if err != nil {
 		slog.Error("Unable to execute workflow", "error", err)
 		os.Exit(1)
 	}
+	slog.Info("Started order workflow", "workflowID", we.GetID())
 
-	var result string
+	var result temporal.Result
 	err = we.Get(context.Background(), &result)
 	if err != nil {
+		var appErr *sdktemporal.ApplicationError
+		if errors.As(err, &appErr) && appErr.Type() == temporal.OrderFailedErrorType && appErr.HasDetails() {
+			if detailsErr := appErr.Details(&result); detailsErr == nil {
+				slog.Error("Order failed", "status", result.Status, "compensation", result.Compensation)
+			}
+		}
 		slog.Error("Unable get workflow result", "error", err)
 		os.Exit(1)
 	}
 
+	slog.Info("Workflow completed",
+		"status", result.Status,
+		"cancellation", result.Cancellation,
+		"compensation", result.Compensation,
+		"return", result.Return,
+		"backorder", result.Backorder,
+	)
+}
+
+// updateOrder sends a lifecycle update to an existing order and reports the
+// status the order moved to, or why the update was rejected.
+func updateOrder(c client.Client, workflowID, update string, req any) {
+	switch update {
+	case temporal.PickOrderUpdate, temporal.ShipOrderUpdate, temporal.ShipItemsUpdate, temporal.OrderDeliveredUpdate,
+		temporal.CancelOrderUpdate, temporal.RequestReturnUpdate, temporal.ReceiveReturnUpdate:
+	default:
+		slog.Error("Unknown update", "update", update)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	handle, err := c.UpdateWorkflow(context.Background(), client.UpdateWorkflowOptions{
+		WorkflowID:   workflowID,
+		UpdateName:   update,
+		Args:         []interface{}{req},
+		WaitForStage: client.WorkflowUpdateStageCompleted,
+	})
+	if err != nil {
+		slog.Error("Update rejected", "update", update, "error", err)
+		os.Exit(1)
+	}
+
+	var status temporal.OrderStatus
+	if err := handle.Get(context.Background(), &status); err != nil {
+		slog.Error("Update failed", "update", update, "error", err)
+		os.Exit(1)
+	}
+
+	slog.Info("Update applied", "update", update, "status", status)
+}
+
+// reviewOrder approves or rejects an existing order held for fraud review. The
+// order is queried first, as the workflow ignores decisions for an order that
+// is not ON_HOLD.
+func reviewOrder(c client.Client, workflowID, decision string, req temporal.TransitionRequest) {
+	var signal string
+	switch decision {
+	case "approve":
+		signal = temporal.ApproveOrderSignal
+	case "reject":
+		signal = temporal.RejectOrderSignal
+	default:
+		slog.Error("Unknown review decision, expected approve or reject", "review", decision)
+		flag.Usage()
+		os.Exit(1)
+	}
+	if req.Actor == "" {
+		slog.Error("actor is required to review an order")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	val, err := c.QueryWorkflow(context.Background(), workflowID, "", temporal.OrderStatusQuery)
+	if err != nil {
+		slog.Error("Unable to query order status", "error", err)
+		os.Exit(1)
+	}
+	var view temporal.OrderStatusView
+	if err := val.Get(&view); err != nil {
+		slog.Error("Unable to decode order status", "error", err)
+		os.Exit(1)
+	}
+	if view.Status != temporal.OnHold {
+		slog.Error("Order is not held for fraud review", "status", view.Status)
+		os.Exit(1)
+	}
+
+	if err := c.SignalWorkflow(context.Background(), workflowID, "", signal, req); err != nil {
+		slog.Error("Unable to signal review", "signal", signal, "error", err)
+		os.Exit(1)
+	}
+	slog.Info("Review signalled", "workflowID", workflowID, "signal", signal)
 }
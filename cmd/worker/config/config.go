@@ -4,14 +4,26 @@ import (
 	"fmt"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/fraud"
 	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/inventory"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/orderstore"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/payment"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/pricing"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/tax"
 	"github.com/pulinau/demo-temporal-order-processor/internal/temporal"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Temporal     temporal.Config  `yaml:"temporal" validate:"required"`
-	InventoryAPI inventory.Config `yaml:"inventoryApi" validate:"required"`
+	Temporal     temporal.Config           `yaml:"temporal" validate:"required"`
+	InventoryAPI inventory.Config          `yaml:"inventoryApi" validate:"required"`
+	PaymentAPI   payment.Config            `yaml:"paymentApi" validate:"required"`
+	OrderStore   orderstore.Config         `yaml:"orderStore" validate:"required"`
+	Processing   temporal.ProcessingConfig `yaml:"processing"`
+	Pricing      pricing.Config            `yaml:"pricing"`
+	Tax          tax.Config                `yaml:"tax"`
+	Fraud        fraud.Config              `yaml:"fraud"`
 }
 
 // LoadConfig reads configuration from the specified file path using Viper
@@ -23,8 +35,16 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	// Decode money amounts (decimal.Decimal) and other text-encoded types
+	// from strings in addition to viper's default hooks.
+	decodeHook := viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.TextUnmarshallerHookFunc(),
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	))
+
 	var cfg Config
-	if err := v.Unmarshal(&cfg); err != nil {
+	if err := v.Unmarshal(&cfg, decodeHook); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
@@ -7,7 +7,12 @@ import (
 	"os"
 
 	config "github.com/pulinau/demo-temporal-order-processor/cmd/worker/config"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/fraud"
 	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/inventory"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/orderstore"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/payment"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/pricing"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/tax"
 	"github.com/pulinau/demo-temporal-order-processor/internal/temporal"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/worker"
@@ -43,8 +48,135 @@ func main() {
 	// Create the Temporal worker,
 	w := worker.New(c, cfg.Temporal.TaskQueueName, worker.Options{})
 
-	// inject HTTP client into the Activities Struct,
-	activities := temporal.NewOrderActivities(inventory.NewClient(cfg.InventoryAPI.BaseURL))
+	orderRepository, err := orderstore.NewFileRepository(cfg.OrderStore.Dir)
+	if err != nil {
+		slog.Error("Unable to create order store", "error", err)
+		os.Exit(1)
+	}
+
+	inventoryAuth, err := inventory.NewAuthenticator(cfg.InventoryAPI.Auth)
+	if err != nil {
+		slog.Error("Unable to configure inventory API authentication", "error", err)
+		os.Exit(1)
+	}
+
+	// inject HTTP clients into the Activities Struct,
+	inventoryOptions := func(logArgs ...any) []inventory.ClientOption {
+		return []inventory.ClientOption{
+			inventory.WithAuthenticator(inventoryAuth),
+			inventory.WithCheckConcurrency(cfg.InventoryAPI.CheckConcurrency),
+			inventory.WithCircuitBreaker(cfg.InventoryAPI.CircuitBreaker, func(from, to inventory.CircuitState) {
+				slog.Warn("Inventory circuit breaker changed state", append(logArgs, "from", from, "to", to)...)
+			}),
+			inventory.WithRateLimit(cfg.InventoryAPI.RateLimit),
+		}
+	}
+	newInventoryClient := func(baseURL string, logArgs ...any) *inventory.Client {
+		return inventory.NewClient(baseURL, inventoryOptions(logArgs...)...)
+	}
+	inventoryClient := newInventoryClient(cfg.InventoryAPI.BaseURL)
+
+	var inventoryChecker interface {
+		temporal.InventoryChecker
+		temporal.BatchInventoryChecker
+	} = inventoryClient
+	if cfg.InventoryAPI.Transport == inventory.TransportGRPC {
+		grpcClient, err := inventory.DialGRPC(cfg.InventoryAPI.GRPC, inventoryOptions("transport", inventory.TransportGRPC)...)
+		if err != nil {
+			slog.Error("Unable to create inventory gRPC client", "error", err)
+			os.Exit(1)
+		}
+		defer grpcClient.Close()
+		inventoryChecker = grpcClient
+	}
+
+	// Every cache logs its stats when the worker stops.
+	var logCacheStats []func()
+	defer func() {
+		for _, logStats := range logCacheStats {
+			logStats()
+		}
+	}()
+	newInventoryCache := func(checker inventory.AvailabilityChecker, logArgs ...any) *inventory.CachingChecker {
+		cache := inventory.NewCachingChecker(checker, cfg.InventoryAPI.Cache)
+		logCacheStats = append(logCacheStats, func() {
+			stats := cache.Stats()
+			slog.Info("Inventory cache stats", append(logArgs, "hits", stats.Hits, "misses", stats.Misses, "entries", stats.Entries)...)
+		})
+		return cache
+	}
+	// Availability checks go through the cache only if it is enabled, as a
+	// cached result may be stale.
+	if cfg.InventoryAPI.Cache.Enabled {
+		inventoryChecker = newInventoryCache(inventoryChecker)
+	}
+
+	// Each warehouse has its own inventory service, reached over HTTP with
+	// the same client settings.
+	warehouses := make([]temporal.Warehouse, 0, len(cfg.InventoryAPI.Warehouses))
+	for _, wh := range cfg.InventoryAPI.Warehouses {
+		client := newInventoryClient(wh.BaseURL, "warehouse", wh.Name)
+		warehouse := temporal.Warehouse{
+			Name:      wh.Name,
+			Priority:  wh.Priority,
+			Country:   wh.Country,
+			Region:    wh.Region,
+			Inventory: client,
+			Reserver:  client,
+		}
+		if cfg.InventoryAPI.Cache.Enabled {
+			warehouse.Inventory = newInventoryCache(client, "warehouse", wh.Name)
+		}
+		warehouses = append(warehouses, warehouse)
+	}
+
+	paymentClient := payment.NewClient(cfg.PaymentAPI.BaseURL)
+	activityOptions := []temporal.ActivityOption{
+		temporal.WithBatchInventoryChecker(inventoryChecker),
+		temporal.WithInventoryReserver(inventoryClient),
+		temporal.WithWarehouses(temporal.RoutingStrategy(cfg.InventoryAPI.Routing), warehouses...),
+		temporal.WithPaymentGateway(paymentClient),
+		temporal.WithOrderRepository(orderRepository),
+		temporal.WithProcessingConfig(cfg.Processing),
+	}
+
+	// Orders are charged the prices they were placed with unless pricing
+	// rules are configured.
+	if cfg.Pricing.RulesFile != "" {
+		pricingEngine, err := pricing.NewRulesEngine(cfg.Pricing.RulesFile)
+		if err != nil {
+			slog.Error("Unable to load pricing rules", "error", err)
+			os.Exit(1)
+		}
+		activityOptions = append(activityOptions, temporal.WithPricingEngine(pricingEngine))
+	}
+
+	// Orders are taxed by the tax service if one is configured, else at the
+	// rates of their jurisdiction if any are listed, else at the flat
+	// processing tax rate.
+	switch {
+	case cfg.Tax.BaseURL != "":
+		activityOptions = append(activityOptions, temporal.WithTaxCalculator(tax.NewClient(cfg.Tax.BaseURL)))
+	case len(cfg.Tax.Jurisdictions) > 0:
+		taxCalculator, err := tax.NewTableCalculator(cfg.Tax.Jurisdictions)
+		if err != nil {
+			slog.Error("Unable to load tax jurisdictions", "error", err)
+			os.Exit(1)
+		}
+		activityOptions = append(activityOptions, temporal.WithTaxCalculator(taxCalculator))
+	}
+
+	// Every order is accepted unless fraud screening is enabled.
+	if cfg.Fraud.Enabled {
+		fraudScorer, err := fraud.NewRulesScorer(cfg.Fraud.Rules)
+		if err != nil {
+			slog.Error("Unable to load fraud rules", "error", err)
+			os.Exit(1)
+		}
+		activityOptions = append(activityOptions, temporal.WithFraudScorer(fraudScorer))
+	}
+
+	activities := temporal.NewOrderActivities(inventoryChecker, activityOptions...)
 
 	// Register Workflow and Activities
 	w.RegisterWorkflow(temporal.ProccessOrder)
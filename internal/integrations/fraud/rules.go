@@ -0,0 +1,160 @@
+// Package fraud screens orders for fraud with rules on order value, how often
+// a customer orders and whether the order's addresses match.
+package fraud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pulinau/demo-temporal-order-processor/internal/temporal"
+	"github.com/shopspring/decimal"
+)
+
+type Config struct {
+	// Enabled screens orders with Rules. Every order is accepted otherwise.
+	Enabled bool  `yaml:"enabled"`
+	Rules   Rules `yaml:"rules"`
+}
+
+// Rules add up to the score of an order. An order is held for review once its
+// score reaches ReviewScore, and rejected once it reaches RejectScore.
+type Rules struct {
+	ReviewScore int `yaml:"reviewScore"`
+	RejectScore int `yaml:"rejectScore"`
+	// OrderValue scores orders by their total, including tax and shipping.
+	// Only the highest threshold an order reaches counts.
+	OrderValue []ValueThreshold `yaml:"orderValue"`
+	Velocity   Velocity         `yaml:"velocity"`
+	// AddressMismatchScore is added for an order shipped to a country other
+	// than the country of its billing address.
+	AddressMismatchScore int `yaml:"addressMismatchScore"`
+}
+
+// ValueThreshold adds Score to orders with a total of at least Total, in the
+// order's currency.
+type ValueThreshold struct {
+	Total decimal.Decimal `yaml:"total"`
+	Score int             `yaml:"score"`
+}
+
+// Velocity scores customers who place many orders in a short time.
+type Velocity struct {
+	// MaxOrders is how many orders a customer can place within Window before
+	// Score is added to each further one. The rule is off if it is zero.
+	MaxOrders int           `yaml:"maxOrders"`
+	Window    time.Duration `yaml:"window"`
+	Score     int           `yaml:"score"`
+}
+
+// RulesScorer is a temporal.FraudScorer that scores orders with Rules. It
+// counts the orders of each customer in memory, so velocity is per worker. It
+// is intended for running the worker locally.
+type RulesScorer struct {
+	rules Rules
+
+	mu sync.Mutex
+	// orders is when each recent order of a customer was screened, by order
+	// ID so that a retried screening is only counted once.
+	orders map[string]map[uuid.UUID]time.Time
+}
+
+// NewRulesScorer checks rules and returns a scorer that screens orders with
+// them.
+func NewRulesScorer(rules Rules) (*RulesScorer, error) {
+	if err := checkRules(rules); err != nil {
+		return nil, fmt.Errorf("invalid fraud rules: %w", err)
+	}
+	return &RulesScorer{
+		rules:  rules,
+		orders: make(map[string]map[uuid.UUID]time.Time),
+	}, nil
+}
+
+func checkRules(rules Rules) error {
+	if rules.ReviewScore <= 0 || rules.RejectScore <= rules.ReviewScore {
+		return errors.New("reviewScore must be positive and less than rejectScore")
+	}
+	for _, t := range rules.OrderValue {
+		if !t.Total.IsPositive() || t.Score <= 0 {
+			return errors.New("order value thresholds need a positive total and score")
+		}
+	}
+	if rules.Velocity.MaxOrders < 0 || (rules.Velocity.MaxOrders > 0 && (rules.Velocity.Window <= 0 || rules.Velocity.Score <= 0)) {
+		return errors.New("velocity needs a positive window and score")
+	}
+	if rules.AddressMismatchScore < 0 {
+		return errors.New("addressMismatchScore must not be negative")
+	}
+	return nil
+}
+
+// ScoreOrder adds up the score of every rule the order breaks.
+func (s *RulesScorer) ScoreOrder(ctx context.Context, req temporal.ScreeningRequest) (temporal.FraudAssessment, error) {
+	var assessment temporal.FraudAssessment
+	add := func(score int, reason string) {
+		assessment.Score += score
+		assessment.Reasons = append(assessment.Reasons, reason)
+	}
+
+	var value *ValueThreshold
+	for i, t := range s.rules.OrderValue {
+		if req.Total.GreaterThanOrEqual(t.Total) && (value == nil || t.Total.GreaterThan(value.Total)) {
+			value = &s.rules.OrderValue[i]
+		}
+	}
+	if value != nil {
+		add(value.Score, fmt.Sprintf("order total of %s %s is at least %s", req.Total.StringFixed(2), req.Order.Currency, value.Total))
+	}
+
+	if orders := s.recordOrder(req.Order, time.Now()); s.rules.Velocity.MaxOrders > 0 && orders > s.rules.Velocity.MaxOrders {
+		add(s.rules.Velocity.Score, fmt.Sprintf("customer placed %d orders within %s", orders, s.rules.Velocity.Window))
+	}
+
+	shipping, billing := req.Order.ShippingAddress, req.Order.BillingAddress
+	if s.rules.AddressMismatchScore > 0 && shipping != nil && billing != nil && !strings.EqualFold(shipping.Country, billing.Country) {
+		add(s.rules.AddressMismatchScore, fmt.Sprintf("shipping country %s differs from billing country %s", shipping.Country, billing.Country))
+	}
+
+	switch {
+	case assessment.Score >= s.rules.RejectScore:
+		assessment.Decision = temporal.FraudReject
+	case assessment.Score >= s.rules.ReviewScore:
+		assessment.Decision = temporal.FraudReview
+	default:
+		assessment.Decision = temporal.FraudAccept
+	}
+	return assessment, nil
+}
+
+// recordOrder records that the customer of order placed it at now and returns
+// how many orders the customer has placed within the velocity window.
+// Orders without a customer are not counted.
+func (s *RulesScorer) recordOrder(order temporal.Order, now time.Time) int {
+	if order.CustomerID == "" || s.rules.Velocity.MaxOrders == 0 {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders, ok := s.orders[order.CustomerID]
+	if !ok {
+		orders = make(map[uuid.UUID]time.Time)
+		s.orders[order.CustomerID] = orders
+	}
+	if _, ok := orders[order.ID]; !ok {
+		orders[order.ID] = now
+	}
+
+	for id, at := range orders {
+		if now.Sub(at) > s.rules.Velocity.Window {
+			delete(orders, id)
+		}
+	}
+	return len(orders)
+}
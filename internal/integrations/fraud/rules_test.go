@@ -0,0 +1,156 @@
+package fraud_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/fraud"
+	"github.com/pulinau/demo-temporal-order-processor/internal/temporal"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRulesScorer(t *testing.T) {
+	suite.Run(t, new(RulesScorerTestSuite))
+}
+
+type RulesScorerTestSuite struct {
+	suite.Suite
+}
+
+var rules = fraud.Rules{
+	ReviewScore: 50,
+	RejectScore: 100,
+	OrderValue: []fraud.ValueThreshold{
+		{Total: decimal.RequireFromString("1000"), Score: 30},
+		{Total: decimal.RequireFromString("5000"), Score: 100},
+	},
+	Velocity:             fraud.Velocity{MaxOrders: 2, Window: time.Hour, Score: 50},
+	AddressMismatchScore: 30,
+}
+
+// request returns a request to screen an order for total, shipped to
+// shippingCountry and billed to billingCountry.
+func request(customerID, total, shippingCountry, billingCountry string) temporal.ScreeningRequest {
+	return temporal.ScreeningRequest{
+		Order: temporal.Order{
+			ID:              uuid.New(),
+			CustomerID:      customerID,
+			Currency:        "AUD",
+			ShippingAddress: &temporal.Address{Country: shippingCountry},
+			BillingAddress:  &temporal.Address{Country: billingCountry},
+		},
+		Total: decimal.RequireFromString(total),
+	}
+}
+
+func (s *RulesScorerTestSuite) TestScoreOrder() {
+	tests := []struct {
+		name         string
+		req          temporal.ScreeningRequest
+		wantDecision temporal.FraudDecision
+		wantScore    int
+		wantReasons  int
+	}{
+		{
+			name:         "Accepted",
+			req:          request("customer-1", "100.00", "AU", "AU"),
+			wantDecision: temporal.FraudAccept,
+		},
+		{
+			name:         "High value",
+			req:          request("customer-1", "1500.00", "AU", "AU"),
+			wantDecision: temporal.FraudAccept,
+			wantScore:    30,
+			wantReasons:  1,
+		},
+		{
+			name:         "High value and address mismatch",
+			req:          request("customer-1", "1500.00", "AU", "NZ"),
+			wantDecision: temporal.FraudReview,
+			wantScore:    60,
+			wantReasons:  2,
+		},
+		{
+			name:         "Only the highest value threshold counts",
+			req:          request("customer-1", "5000.00", "AU", "AU"),
+			wantDecision: temporal.FraudReject,
+			wantScore:    100,
+			wantReasons:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			scorer, err := fraud.NewRulesScorer(rules)
+			s.Require().NoError(err)
+
+			// Invoke
+			got, err := scorer.ScoreOrder(context.Background(), tt.req)
+
+			// Assert
+			s.Require().NoError(err)
+			s.Equal(tt.wantDecision, got.Decision)
+			s.Equal(tt.wantScore, got.Score)
+			s.Len(got.Reasons, tt.wantReasons)
+		})
+	}
+}
+
+func (s *RulesScorerTestSuite) TestScoreOrder_Velocity() {
+	// Setup
+	scorer, err := fraud.NewRulesScorer(rules)
+	s.Require().NoError(err)
+
+	first := request("customer-1", "10.00", "AU", "AU")
+	second := request("customer-1", "10.00", "AU", "AU")
+	third := request("customer-1", "10.00", "AU", "AU")
+	other := request("customer-2", "10.00", "AU", "AU")
+
+	// Invoke
+	var got []temporal.FraudDecision
+	for _, req := range []temporal.ScreeningRequest{first, second, first, other, third} {
+		assessment, err := scorer.ScoreOrder(context.Background(), req)
+		s.Require().NoError(err)
+		got = append(got, assessment.Decision)
+	}
+
+	// Assert
+	s.Equal([]temporal.FraudDecision{
+		temporal.FraudAccept,
+		temporal.FraudAccept,
+		temporal.FraudAccept, // The first order again, as when it is retried.
+		temporal.FraudAccept, // Another customer.
+		temporal.FraudReview,
+	}, got)
+}
+
+func (s *RulesScorerTestSuite) TestNewRulesScorer_InvalidRules() {
+	tests := []struct {
+		name  string
+		rules fraud.Rules
+		err   string
+	}{
+		{
+			name:  "Reject score below review score",
+			rules: fraud.Rules{ReviewScore: 50, RejectScore: 40},
+			err:   "reviewScore must be positive and less than rejectScore",
+		},
+		{
+			name:  "Velocity without a window",
+			rules: fraud.Rules{ReviewScore: 50, RejectScore: 100, Velocity: fraud.Velocity{MaxOrders: 3, Score: 50}},
+			err:   "velocity needs a positive window and score",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			_, err := fraud.NewRulesScorer(tt.rules)
+
+			s.Require().ErrorContains(err, tt.err)
+		})
+	}
+}
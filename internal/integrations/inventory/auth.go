@@ -0,0 +1,284 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authentication types accepted in AuthConfig.Type.
+const (
+	AuthNone   = "none"
+	AuthAPIKey = "apiKey"
+	AuthBearer = "bearer"
+	AuthOAuth2 = "oauth2"
+)
+
+// defaultAPIKeyHeader is the header the API key is sent in unless configured
+// otherwise.
+const defaultAPIKeyHeader = "X-API-Key"
+
+// tokenExpiryMargin is how long before it expires an OAuth2 access token is
+// replaced, so a request is not sent with a token that expires in flight.
+const tokenExpiryMargin = 30 * time.Second
+
+type AuthConfig struct {
+	// Type selects how requests are authenticated. Defaults to AuthNone.
+	Type   string       `yaml:"type" validate:"omitempty,oneof=none apiKey bearer oauth2"`
+	APIKey APIKeyConfig `yaml:"apiKey"`
+	Bearer BearerConfig `yaml:"bearer"`
+	OAuth2 OAuth2Config `yaml:"oauth2"`
+}
+
+type APIKeyConfig struct {
+	// Header is the request header the key is sent in. Defaults to
+	// defaultAPIKeyHeader.
+	Header string `yaml:"header"`
+	Key    Secret `yaml:"key"`
+}
+
+type BearerConfig struct {
+	Token Secret `yaml:"token"`
+}
+
+// OAuth2Config configures the OAuth2 client credentials grant.
+type OAuth2Config struct {
+	TokenURL     string   `yaml:"tokenUrl" validate:"omitempty,http_url"`
+	ClientID     string   `yaml:"clientId"`
+	ClientSecret Secret   `yaml:"clientSecret"`
+	Scopes       []string `yaml:"scopes"`
+}
+
+// Secret is a credential given in the config file, or read from an
+// environment variable or a file when the Authenticator is created. Only one
+// of the fields should be set.
+type Secret struct {
+	Value string `yaml:"value"`
+	Env   string `yaml:"env"`
+	File  string `yaml:"file"`
+}
+
+// Resolve returns the secret. Surrounding whitespace, such as the trailing
+// newline of a mounted file, is removed.
+func (s Secret) Resolve() (string, error) {
+	var value string
+	switch {
+	case s.Value != "":
+		value = s.Value
+	case s.Env != "":
+		v, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+		value = v
+	case s.File != "":
+		data, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		value = string(data)
+	default:
+		return "", errors.New("secret is not set")
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", errors.New("secret is empty")
+	}
+	return value, nil
+}
+
+// Authenticator adds credentials to a request before the Client sends it.
+type Authenticator interface {
+	Authenticate(ctx context.Context, req *http.Request) error
+}
+
+// tokenInvalidator is implemented by Authenticators that cache a credential
+// which the inventory service may reject before it is due to expire.
+type tokenInvalidator interface {
+	// invalidateToken discards the cached credential if it is the one sent
+	// in the rejected Authorization header, so that a request rejected
+	// after another has already replaced it does not discard the new one.
+	invalidateToken(authorization string)
+}
+
+// NewAuthenticator creates the Authenticator described by cfg and resolves its
+// secrets. It returns nil if requests are not authenticated.
+func NewAuthenticator(cfg AuthConfig) (Authenticator, error) {
+	switch cfg.Type {
+	case "", AuthNone:
+		return nil, nil
+
+	case AuthAPIKey:
+		key, err := cfg.APIKey.Key.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("api key: %w", err)
+		}
+		header := cfg.APIKey.Header
+		if header == "" {
+			header = defaultAPIKeyHeader
+		}
+		return &headerAuth{header: header, value: key}, nil
+
+	case AuthBearer:
+		token, err := cfg.Bearer.Token.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("bearer token: %w", err)
+		}
+		return &headerAuth{header: "Authorization", value: "Bearer " + token}, nil
+
+	case AuthOAuth2:
+		if cfg.OAuth2.TokenURL == "" {
+			return nil, errors.New("oauth2: token url is required")
+		}
+		if cfg.OAuth2.ClientID == "" {
+			return nil, errors.New("oauth2: client id is required")
+		}
+		secret, err := cfg.OAuth2.ClientSecret.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("oauth2 client secret: %w", err)
+		}
+		return &clientCredentials{
+			cfg:          cfg.OAuth2,
+			clientSecret: secret,
+			httpClient: &http.Client{
+				Timeout: 10 * time.Second,
+			},
+			now: time.Now,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown auth type %q", cfg.Type)
+	}
+}
+
+// headerAuth sets a fixed header on every request.
+type headerAuth struct {
+	header string
+	value  string
+}
+
+func (a *headerAuth) Authenticate(ctx context.Context, req *http.Request) error {
+	req.Header.Set(a.header, a.value)
+	return nil
+}
+
+// clientCredentials sends an OAuth2 access token obtained with the client
+// credentials grant. The token is cached until shortly before it expires or
+// the inventory service rejects it.
+type clientCredentials struct {
+	cfg          OAuth2Config
+	clientSecret string
+	httpClient   *http.Client
+	now          func() time.Time
+
+	// mu is held while a token is fetched so concurrent requests wait for
+	// it instead of fetching their own.
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error,omitempty"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func (a *clientCredentials) Authenticate(ctx context.Context, req *http.Request) error {
+	token, err := a.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (a *clientCredentials) invalidateToken(authorization string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token != "" && authorization == "Bearer "+a.token {
+		a.token = ""
+	}
+}
+
+func (a *clientCredentials) accessToken(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// A token without an expiry is used until it is rejected.
+	if a.token != "" && (a.expiry.IsZero() || a.now().Before(a.expiry)) {
+		return a.token, nil
+	}
+
+	token, expiresIn, err := a.fetchToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	a.token = token
+	a.expiry = time.Time{}
+	if expiresIn > 0 {
+		a.expiry = a.now().Add(expiresIn - min(tokenExpiryMargin, expiresIn/2))
+	}
+	return a.token, nil
+}
+
+// fetchToken requests a new access token from the token endpoint. The client
+// credentials are sent with HTTP Basic authentication.
+func (a *clientCredentials) fetchToken(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.cfg.Scopes, " "))
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create token request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.SetBasicAuth(url.QueryEscape(a.cfg.ClientID), url.QueryEscape(a.clientSecret))
+
+	httpResp, err := a.httpClient.Do(httpReq)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to request access token: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read token response body: %w", err)
+	}
+
+	var tokenResp tokenResponse
+	if httpResp.StatusCode != http.StatusOK {
+		_ = json.Unmarshal(respBody, &tokenResp)
+		message := strings.TrimSpace(tokenResp.Error + " " + tokenResp.ErrorDescription)
+		resp := &response{statusCode: httpResp.StatusCode, header: httpResp.Header, body: respBody}
+		return "", 0, fmt.Errorf("failed to request access token: %w", statusError(resp, message, ErrUnauthorized))
+	}
+
+	if err := json.Unmarshal(respBody, &tokenResp); err != nil {
+		return "", 0, fmt.Errorf("failed to unmarshal token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return "", 0, errors.New("token response has no access token")
+	}
+	if tokenResp.TokenType != "" && !strings.EqualFold(tokenResp.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("unsupported token type %q", tokenResp.TokenType)
+	}
+
+	return tokenResp.AccessToken, time.Duration(tokenResp.ExpiresIn) * time.Second, nil
+}
@@ -0,0 +1,321 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestAuth(t *testing.T) {
+	suite.Run(t, new(AuthTestSuite))
+}
+
+type AuthTestSuite struct {
+	suite.Suite
+
+	now time.Time
+
+	// tokenServer issues access tokens "token-1", "token-2", ... that
+	// expire after expiresIn seconds.
+	tokenServer *httptest.Server
+	mu          sync.Mutex
+	tokens      int
+	expiresIn   int
+}
+
+func (s *AuthTestSuite) SetupTest() {
+	s.now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.tokens = 0
+	s.expiresIn = 120
+	s.tokenServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, ok := r.BasicAuth()
+		if !ok || clientID != "order-processor" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error": "invalid_client", "error_description": "bad credentials"}`))
+			return
+		}
+		s.Equal("client_credentials", r.PostFormValue("grant_type"))
+		s.Equal("inventory:read inventory:write", r.PostFormValue("scope"))
+
+		s.mu.Lock()
+		s.tokens++
+		body := fmt.Sprintf(`{"access_token": "token-%d", "token_type": "Bearer", "expires_in": %d}`, s.tokens, s.expiresIn)
+		s.mu.Unlock()
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func (s *AuthTestSuite) TearDownTest() {
+	s.tokenServer.Close()
+}
+
+// oauth2 creates a client credentials Authenticator using the token server
+// and the suite's clock.
+func (s *AuthTestSuite) oauth2(secret string) *clientCredentials {
+	auth, err := NewAuthenticator(AuthConfig{
+		Type: AuthOAuth2,
+		OAuth2: OAuth2Config{
+			TokenURL:     s.tokenServer.URL,
+			ClientID:     "order-processor",
+			ClientSecret: Secret{Value: secret},
+			Scopes:       []string{"inventory:read", "inventory:write"},
+		},
+	})
+	s.Require().NoError(err)
+	credentials := auth.(*clientCredentials)
+	credentials.now = func() time.Time { return s.now }
+	return credentials
+}
+
+// authorization returns the Authorization header auth sets on a request.
+func (s *AuthTestSuite) authorization(auth Authenticator) string {
+	req := httptest.NewRequest(http.MethodPost, "/inventory/check", nil)
+	s.Require().NoError(auth.Authenticate(context.Background(), req))
+	return req.Header.Get("Authorization")
+}
+
+func (s *AuthTestSuite) TestOAuth2_CachesToken() {
+	auth := s.oauth2("s3cret")
+
+	s.Equal("Bearer token-1", s.authorization(auth))
+	s.Equal("Bearer token-1", s.authorization(auth))
+
+	s.Equal(1, s.tokens, "the cached token should be reused")
+}
+
+func (s *AuthTestSuite) TestOAuth2_RefreshesBeforeExpiry() {
+	tests := []struct {
+		name      string
+		expiresIn int
+		// refreshAfter is how long after it was issued the token is
+		// replaced: the expiry less tokenExpiryMargin, or less half the
+		// lifetime of a short-lived token.
+		refreshAfter time.Duration
+	}{
+		{name: "Long-lived token", expiresIn: 120, refreshAfter: 90 * time.Second},
+		{name: "Short-lived token", expiresIn: 20, refreshAfter: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			s.tokens = 0
+			s.expiresIn = tt.expiresIn
+			auth := s.oauth2("s3cret")
+			s.Require().Equal("Bearer token-1", s.authorization(auth))
+
+			// Invoke
+			s.now = s.now.Add(tt.refreshAfter - time.Second)
+			beforeMargin := s.authorization(auth)
+			s.now = s.now.Add(time.Second)
+			atMargin := s.authorization(auth)
+
+			// Assert
+			s.Equal("Bearer token-1", beforeMargin)
+			s.Equal("Bearer token-2", atMargin)
+		})
+	}
+}
+
+func (s *AuthTestSuite) TestOAuth2_TokenWithoutExpiry() {
+	s.expiresIn = 0
+	auth := s.oauth2("s3cret")
+
+	s.Equal("Bearer token-1", s.authorization(auth))
+	s.now = s.now.Add(24 * time.Hour)
+	s.Equal("Bearer token-1", s.authorization(auth), "a token without an expiry should be used until rejected")
+}
+
+func (s *AuthTestSuite) TestOAuth2_RejectedCredentials() {
+	auth := s.oauth2("wrong")
+
+	req := httptest.NewRequest(http.MethodPost, "/inventory/check", nil)
+	err := auth.Authenticate(context.Background(), req)
+
+	s.Require().ErrorIs(err, ErrUnauthorized)
+	s.ErrorContains(err, "invalid_client bad credentials")
+}
+
+func (s *AuthTestSuite) TestClient_RefreshesRejectedToken() {
+	// Setup
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Header.Get("Authorization"))
+		// The first token has been revoked.
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"available": true}`))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, WithAuthenticator(s.oauth2("s3cret")))
+
+	// Invoke
+	available, err := client.CheckInventory(context.Background(), uuid.New(), 1)
+
+	// Assert
+	s.Require().NoError(err)
+	s.True(available)
+	s.Equal([]string{"Bearer token-1", "Bearer token-2"}, sent)
+}
+
+func (s *AuthTestSuite) TestClient_RetriesRejectedTokenOnce() {
+	// Setup
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, WithAuthenticator(s.oauth2("s3cret")))
+
+	// Invoke
+	_, err := client.CheckInventory(context.Background(), uuid.New(), 1)
+
+	// Assert
+	s.Require().ErrorIs(err, ErrUnauthorized)
+	s.Equal(2, requests)
+	s.Equal(2, s.tokens)
+}
+
+func (s *AuthTestSuite) TestClient_ConcurrentRejectionsRefreshOnce() {
+	// Setup
+	const requests = 5
+	var rejected sync.WaitGroup
+	rejected.Add(requests)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first token has been revoked. Every request is sent with it
+		// before any of them is rejected.
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			rejected.Done()
+			rejected.Wait()
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"available": true}`))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, WithAuthenticator(s.oauth2("s3cret")))
+
+	// Invoke
+	var wg sync.WaitGroup
+	for range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			available, err := client.CheckInventory(context.Background(), uuid.New(), 1)
+			s.NoError(err)
+			s.True(available)
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	s.Equal(2, s.tokens, "a token replaced after a rejection should not be discarded by later rejections of the old one")
+}
+
+func (s *AuthTestSuite) TestOAuth2_InvalidatesOnlyRejectedToken() {
+	auth := s.oauth2("s3cret")
+	rejected := s.authorization(auth)
+
+	auth.invalidateToken(rejected)
+	s.Equal("Bearer token-2", s.authorization(auth))
+	auth.invalidateToken(rejected)
+
+	s.Equal("Bearer token-2", s.authorization(auth), "a stale rejection should not discard the current token")
+	s.Equal(2, s.tokens)
+}
+
+func (s *AuthTestSuite) TestNewAuthenticator() {
+	tests := []struct {
+		name   string
+		cfg    AuthConfig
+		header string
+		value  string
+	}{
+		{
+			name:   "API key",
+			cfg:    AuthConfig{Type: AuthAPIKey, APIKey: APIKeyConfig{Key: Secret{Value: "key-1"}}},
+			header: "X-API-Key",
+			value:  "key-1",
+		},
+		{
+			name:   "API key in custom header",
+			cfg:    AuthConfig{Type: AuthAPIKey, APIKey: APIKeyConfig{Header: "X-Inventory-Key", Key: Secret{Value: "key-1"}}},
+			header: "X-Inventory-Key",
+			value:  "key-1",
+		},
+		{
+			name:   "Bearer token",
+			cfg:    AuthConfig{Type: AuthBearer, Bearer: BearerConfig{Token: Secret{Value: "token"}}},
+			header: "Authorization",
+			value:  "Bearer token",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			auth, err := NewAuthenticator(tt.cfg)
+			s.Require().NoError(err)
+
+			req := httptest.NewRequest(http.MethodPost, "/inventory/check", nil)
+			s.Require().NoError(auth.Authenticate(context.Background(), req))
+
+			s.Equal(tt.value, req.Header.Get(tt.header))
+		})
+	}
+}
+
+func (s *AuthTestSuite) TestNewAuthenticator_None() {
+	auth, err := NewAuthenticator(AuthConfig{})
+
+	s.Require().NoError(err)
+	s.Nil(auth)
+}
+
+func (s *AuthTestSuite) TestSecretResolve() {
+	dir := s.T().TempDir()
+	secretFile := filepath.Join(dir, "secret")
+	s.Require().NoError(os.WriteFile(secretFile, []byte("from-file\n"), 0o600))
+	emptyFile := filepath.Join(dir, "empty")
+	s.Require().NoError(os.WriteFile(emptyFile, []byte("\n"), 0o600))
+	s.T().Setenv("INVENTORY_TEST_SECRET", " from-env ")
+
+	tests := []struct {
+		name    string
+		secret  Secret
+		want    string
+		wantErr string
+	}{
+		{name: "Value", secret: Secret{Value: "from-config"}, want: "from-config"},
+		{name: "Environment variable", secret: Secret{Env: "INVENTORY_TEST_SECRET"}, want: "from-env"},
+		{name: "File", secret: Secret{File: secretFile}, want: "from-file"},
+		{name: "Unset environment variable", secret: Secret{Env: "INVENTORY_TEST_MISSING"}, wantErr: "environment variable INVENTORY_TEST_MISSING is not set"},
+		{name: "Missing file", secret: Secret{File: filepath.Join(dir, "missing")}, wantErr: "failed to read secret file"},
+		{name: "Empty file", secret: Secret{File: emptyFile}, wantErr: "secret is empty"},
+		{name: "Not set", secret: Secret{}, wantErr: "secret is not set"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			got, err := tt.secret.Resolve()
+
+			if tt.wantErr != "" {
+				s.Require().ErrorContains(err, tt.wantErr)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(tt.want, got)
+		})
+	}
+}
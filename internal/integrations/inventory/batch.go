@@ -0,0 +1,142 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+)
+
+type CheckInventoryBatchRequest struct {
+	Items []CheckInventoryRequest `json:"items"`
+}
+
+type CheckInventoryBatchResult struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Available bool      `json:"available"`
+	Message   string    `json:"message,omitempty"`
+}
+
+type CheckInventoryBatchResponse struct {
+	Results []CheckInventoryBatchResult `json:"results"`
+	Message string                      `json:"message,omitempty"`
+}
+
+// CheckInventoryBatch checks the requested quantity of every product in one
+// request. If the inventory service does not provide the batch endpoint the
+// products are checked individually, at most checkConcurrency at a time, and
+// the batch endpoint is not tried again for batchRecheckInterval.
+func (c *Client) CheckInventoryBatch(ctx context.Context, items map[uuid.UUID]int32) (map[uuid.UUID]bool, error) {
+	if c.batch.supported() {
+		available, supported, err := c.checkInventoryBatch(ctx, items)
+		if supported {
+			return available, err
+		}
+		c.batch.markUnsupported()
+	}
+
+	return checkInventoryConcurrently(ctx, c, items, c.checkConcurrency)
+}
+
+// checkInventoryBatch calls the batch endpoint. It reports false if the
+// endpoint does not exist.
+func (c *Client) checkInventoryBatch(ctx context.Context, items map[uuid.UUID]int32) (map[uuid.UUID]bool, bool, error) {
+	req := CheckInventoryBatchRequest{
+		Items: make([]CheckInventoryRequest, 0, len(items)),
+	}
+	for _, item := range reservationItems(items) {
+		req.Items = append(req.Items, CheckInventoryRequest{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	resp, err := c.post(ctx, "/inventory/check/batch", req)
+	if err != nil {
+		return nil, true, err
+	}
+
+	switch resp.statusCode {
+	case http.StatusOK:
+		var batchResp CheckInventoryBatchResponse
+		if err := json.Unmarshal(resp.body, &batchResp); err != nil {
+			return nil, true, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		available := make(map[uuid.UUID]bool, len(items))
+		for _, result := range batchResp.Results {
+			available[result.ProductID] = result.Available
+		}
+		for productID := range items {
+			if _, ok := available[productID]; !ok {
+				return nil, true, fmt.Errorf("incomplete batch response: no result for product %s", productID)
+			}
+		}
+		return available, true, nil
+
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return nil, false, nil
+
+	default:
+		var batchResp CheckInventoryBatchResponse
+		_ = json.Unmarshal(resp.body, &batchResp)
+		return nil, true, statusError(resp, batchResp.Message, ErrInvalidProduct)
+	}
+}
+
+// batchRecheckInterval is how long a missing batch method is remembered. The
+// inventory service may be upgraded, or the answer may have come from a proxy
+// during a deployment, so the method is tried again once it has passed.
+const batchRecheckInterval = 5 * time.Minute
+
+// batchSupport remembers that the inventory service answered that it has no
+// batch method, so that the method is not tried before every fallback. The
+// zero value assumes the method exists.
+type batchSupport struct {
+	now              func() time.Time
+	unsupportedUntil atomic.Int64
+}
+
+func (b *batchSupport) supported() bool {
+	return b.currentTime().UnixNano() >= b.unsupportedUntil.Load()
+}
+
+func (b *batchSupport) markUnsupported() {
+	b.unsupportedUntil.Store(b.currentTime().Add(batchRecheckInterval).UnixNano())
+}
+
+func (b *batchSupport) currentTime() time.Time {
+	if b.now == nil {
+		return time.Now()
+	}
+	return b.now()
+}
+
+// checkInventoryConcurrently checks each product with checker, at most limit
+// at a time. The first error cancels the remaining checks.
+func checkInventoryConcurrently(ctx context.Context, checker AvailabilityChecker, items map[uuid.UUID]int32, limit int) (map[uuid.UUID]bool, error) {
+	var mu sync.Mutex
+	available := make(map[uuid.UUID]bool, len(items))
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(limit)
+	for productID, quantity := range items {
+		g.Go(func() error {
+			ok, err := checker.CheckInventory(ctx, productID, quantity)
+			if err != nil {
+				return fmt.Errorf("product %s: %w", productID, err)
+			}
+			mu.Lock()
+			available[productID] = ok
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return available, nil
+}
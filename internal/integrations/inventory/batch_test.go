@@ -0,0 +1,245 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestBatch(t *testing.T) {
+	suite.Run(t, new(BatchTestSuite))
+}
+
+// BatchTestSuite runs the Client against an inventory service whose batch
+// endpoint answers with batchStatus, or with the stock of every product if
+// it is 200. Individual checks are answered from the same stock.
+type BatchTestSuite struct {
+	suite.Suite
+
+	now    time.Time
+	server *httptest.Server
+
+	mu          sync.Mutex
+	stock       map[uuid.UUID]int32
+	batchStatus int
+	// omit is left out of batch responses.
+	omit         uuid.UUID
+	batchCalls   int
+	checkCalls   int
+	inFlight     int
+	maxInFlight  int
+	checkLatency time.Duration
+}
+
+func (s *BatchTestSuite) SetupTest() {
+	s.reset()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /inventory/check/batch", s.handleBatch)
+	mux.HandleFunc("POST /inventory/check", s.handleCheck)
+	s.server = httptest.NewServer(mux)
+}
+
+func (s *BatchTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+// reset restores the server's defaults and clears its counters.
+func (s *BatchTestSuite) reset() {
+	s.now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.stock = make(map[uuid.UUID]int32)
+	s.batchStatus = http.StatusOK
+	s.omit = uuid.Nil
+	s.batchCalls, s.checkCalls, s.inFlight, s.maxInFlight = 0, 0, 0, 0
+	s.checkLatency = 0
+}
+
+func (s *BatchTestSuite) handleBatch(w http.ResponseWriter, r *http.Request) {
+	var req CheckInventoryBatchRequest
+	s.NoError(json.NewDecoder(r.Body).Decode(&req))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batchCalls++
+	if s.batchStatus != http.StatusOK {
+		w.WriteHeader(s.batchStatus)
+		return
+	}
+
+	var resp CheckInventoryBatchResponse
+	for _, item := range req.Items {
+		if item.ProductID == s.omit {
+			continue
+		}
+		resp.Results = append(resp.Results, CheckInventoryBatchResult{
+			ProductID: item.ProductID,
+			Available: s.stock[item.ProductID] >= item.Quantity,
+		})
+	}
+	s.NoError(json.NewEncoder(w).Encode(resp))
+}
+
+func (s *BatchTestSuite) handleCheck(w http.ResponseWriter, r *http.Request) {
+	var req CheckInventoryRequest
+	s.NoError(json.NewDecoder(r.Body).Decode(&req))
+
+	s.mu.Lock()
+	s.checkCalls++
+	s.inFlight++
+	s.maxInFlight = max(s.maxInFlight, s.inFlight)
+	available := s.stock[req.ProductID] >= req.Quantity
+	latency := s.checkLatency
+	s.mu.Unlock()
+
+	time.Sleep(latency)
+
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+	s.NoError(json.NewEncoder(w).Encode(CheckInventoryResponse{Available: available}))
+}
+
+// client creates a Client for the server, using the suite's clock for the
+// batch endpoint re-check.
+func (s *BatchTestSuite) client(opts ...ClientOption) *Client {
+	c := NewClient(s.server.URL, opts...)
+	c.batch.now = func() time.Time { return s.now }
+	return c
+}
+
+// items returns n products, every other one in stock, with the quantity of
+// each to check.
+func (s *BatchTestSuite) items(n int) (map[uuid.UUID]int32, map[uuid.UUID]bool) {
+	items := make(map[uuid.UUID]int32, n)
+	want := make(map[uuid.UUID]bool, n)
+	for i := range n {
+		productID := uuid.New()
+		items[productID] = 2
+		if i%2 == 0 {
+			s.stock[productID] = 2
+		}
+		want[productID] = i%2 == 0
+	}
+	return items, want
+}
+
+func (s *BatchTestSuite) TestCheckInventoryBatch() {
+	// Setup
+	items, want := s.items(4)
+	client := s.client()
+
+	// Invoke
+	available, err := client.CheckInventoryBatch(context.Background(), items)
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal(want, available)
+	s.Equal(1, s.batchCalls)
+	s.Zero(s.checkCalls)
+}
+
+func (s *BatchTestSuite) TestIncompleteResponse() {
+	// Setup
+	items, _ := s.items(3)
+	for productID := range items {
+		s.omit = productID
+		break
+	}
+	client := s.client()
+
+	// Invoke
+	_, err := client.CheckInventoryBatch(context.Background(), items)
+
+	// Assert
+	s.Require().ErrorContains(err, "incomplete batch response: no result for product "+s.omit.String())
+	s.Zero(s.checkCalls, "an incomplete response should not fall back to individual checks")
+}
+
+func (s *BatchTestSuite) TestFallsBackWhenUnsupported() {
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{name: "Not found", status: http.StatusNotFound},
+		{name: "Method not allowed", status: http.StatusMethodNotAllowed},
+		{name: "Not implemented", status: http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			s.reset()
+			s.batchStatus = tt.status
+			s.checkLatency = 10 * time.Millisecond
+			items, want := s.items(6)
+			client := s.client(
+				WithCheckConcurrency(2),
+				WithCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1}, nil),
+			)
+
+			// Invoke
+			available, err := client.CheckInventoryBatch(context.Background(), items)
+			s.Require().NoError(err)
+			_, err = client.CheckInventoryBatch(context.Background(), items)
+			s.Require().NoError(err)
+
+			// Assert
+			s.Equal(want, available)
+			s.Equal(1, s.batchCalls, "the missing batch endpoint should be remembered")
+			s.Equal(12, s.checkCalls)
+			s.LessOrEqual(s.maxInFlight, 2, "at most checkConcurrency products should be checked at once")
+			s.Equal(CircuitClosed, client.CircuitState(), "a missing batch endpoint should not open the breaker")
+		})
+	}
+}
+
+func (s *BatchTestSuite) TestRechecksUnsupportedBatchEndpoint() {
+	// Setup
+	s.batchStatus = http.StatusNotFound
+	items, want := s.items(2)
+	client := s.client()
+	_, err := client.CheckInventoryBatch(context.Background(), items)
+	s.Require().NoError(err)
+
+	// The endpoint is deployed, but is not tried again until the re-check
+	// interval has passed.
+	s.batchStatus = http.StatusOK
+	s.now = s.now.Add(batchRecheckInterval - time.Second)
+	_, err = client.CheckInventoryBatch(context.Background(), items)
+	s.Require().NoError(err)
+	s.Require().Equal(1, s.batchCalls)
+
+	// Invoke
+	s.now = s.now.Add(time.Second)
+	available, err := client.CheckInventoryBatch(context.Background(), items)
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal(want, available)
+	s.Equal(2, s.batchCalls)
+	s.Equal(4, s.checkCalls, "the batch endpoint should be used once it is found again")
+}
+
+func (s *BatchTestSuite) TestDoesNotFallBackOnFailure() {
+	// Setup
+	s.batchStatus = http.StatusServiceUnavailable
+	items, _ := s.items(2)
+	client := s.client()
+
+	// Invoke
+	_, err := client.CheckInventoryBatch(context.Background(), items)
+
+	// Assert
+	s.Require().ErrorIs(err, ErrUnavailable)
+	s.Zero(s.checkCalls)
+	_, err = client.CheckInventoryBatch(context.Background(), items)
+	s.Require().ErrorIs(err, ErrUnavailable)
+	s.Equal(2, s.batchCalls, "a failed batch request should not be taken for a missing endpoint")
+}
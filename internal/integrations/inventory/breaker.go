@@ -0,0 +1,208 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of the Client's circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets every request through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects every request until the open timeout has passed.
+	CircuitOpen
+	// CircuitHalfOpen lets one trial request through at a time to find out
+	// whether the inventory service has recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// ErrCircuitOpen is matched by the error returned when the circuit breaker
+// rejects a request without sending it.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned when the circuit breaker rejects a request.
+// The request may succeed if retried after RetryAfter.
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: retry after %s", ErrCircuitOpen, e.RetryAfter)
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+type CircuitBreakerConfig struct {
+	// FailureThreshold is how many consecutive failed requests open the
+	// breaker. Zero disables the breaker.
+	FailureThreshold int `yaml:"failureThreshold" validate:"omitempty,min=1"`
+	// OpenTimeout is how long the breaker stays open before a trial request
+	// is let through. Defaults to defaultOpenTimeout.
+	OpenTimeout time.Duration `yaml:"openTimeout"`
+	// HalfOpenSuccesses is how many trial requests must succeed in a row to
+	// close the breaker again. Defaults to 1.
+	HalfOpenSuccesses int `yaml:"halfOpenSuccesses" validate:"omitempty,min=1"`
+}
+
+const defaultOpenTimeout = 30 * time.Second
+
+// circuitBreaker stops the Client sending requests to an inventory service
+// that keeps failing. A nil *circuitBreaker lets every request through.
+type circuitBreaker struct {
+	cfg      CircuitBreakerConfig
+	now      func() time.Time
+	onChange func(from, to CircuitState)
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	successes int
+	openedAt  time.Time
+	trial     bool
+}
+
+func newCircuitBreaker(cfg CircuitBreakerConfig, onChange func(from, to CircuitState)) *circuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		return nil
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = defaultOpenTimeout
+	}
+	if cfg.HalfOpenSuccesses <= 0 {
+		cfg.HalfOpenSuccesses = 1
+	}
+	return &circuitBreaker{
+		cfg:      cfg,
+		now:      time.Now,
+		onChange: onChange,
+	}
+}
+
+// outcome is what a request that the breaker allowed tells it about the
+// health of the inventory service.
+type outcome int
+
+const (
+	// outcomeHealthy is a response from the service, even one rejecting the
+	// request or its credentials.
+	outcomeHealthy outcome = iota
+	// outcomeUnhealthy is a request that failed in transport, or that the
+	// service answered with a 429 status or a 5xx status other than 501.
+	outcomeUnhealthy
+	// outcomeUnknown is a request that tells nothing about the service, e.g.
+	// because the caller cancelled it or it could not be authenticated.
+	outcomeUnknown
+)
+
+// allow reports whether a request may be sent. Every allowed request must be
+// followed by a call to record.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if wait := b.cfg.OpenTimeout - b.now().Sub(b.openedAt); wait > 0 {
+			return &CircuitOpenError{RetryAfter: wait}
+		}
+		b.setState(CircuitHalfOpen)
+		fallthrough
+
+	case CircuitHalfOpen:
+		if b.trial {
+			// Another trial request is already in flight.
+			return &CircuitOpenError{RetryAfter: b.cfg.OpenTimeout}
+		}
+		b.trial = true
+	}
+
+	return nil
+}
+
+// record updates the breaker with the outcome of an allowed request. An
+// unknown outcome neither counts as a failure nor as a success, and lets
+// another trial request through if it was one.
+func (b *circuitBreaker) record(o outcome) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitClosed:
+		switch o {
+		case outcomeHealthy:
+			b.failures = 0
+		case outcomeUnhealthy:
+			b.failures++
+			if b.failures >= b.cfg.FailureThreshold {
+				b.open()
+			}
+		}
+
+	case CircuitHalfOpen:
+		b.trial = false
+		if o == outcomeUnknown {
+			return
+		}
+		if o == outcomeUnhealthy {
+			b.open()
+			return
+		}
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenSuccesses {
+			b.failures = 0
+			b.successes = 0
+			b.setState(CircuitClosed)
+		}
+	}
+}
+
+func (b *circuitBreaker) currentState() CircuitState {
+	if b == nil {
+		return CircuitClosed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *circuitBreaker) open() {
+	b.openedAt = b.now()
+	b.successes = 0
+	b.setState(CircuitOpen)
+}
+
+func (b *circuitBreaker) setState(state CircuitState) {
+	from := b.state
+	b.state = state
+	if b.onChange != nil && from != state {
+		b.onChange(from, state)
+	}
+}
@@ -0,0 +1,201 @@
+package inventory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	suite.Run(t, new(CircuitBreakerTestSuite))
+}
+
+type CircuitBreakerTestSuite struct {
+	suite.Suite
+
+	now         time.Time
+	breaker     *circuitBreaker
+	transitions []CircuitState
+}
+
+func (s *CircuitBreakerTestSuite) SetupTest() {
+	s.now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.transitions = nil
+	s.breaker = newCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold:  3,
+		OpenTimeout:       30 * time.Second,
+		HalfOpenSuccesses: 2,
+	}, func(from, to CircuitState) {
+		s.transitions = append(s.transitions, to)
+	})
+	s.breaker.now = func() time.Time { return s.now }
+}
+
+// send records a request with the given outcome, which the breaker must allow.
+func (s *CircuitBreakerTestSuite) send(o outcome) {
+	s.Require().NoError(s.breaker.allow())
+	s.breaker.record(o)
+}
+
+// trip opens the breaker with consecutive failures.
+func (s *CircuitBreakerTestSuite) trip() {
+	for range 3 {
+		s.send(outcomeUnhealthy)
+	}
+	s.Require().Equal(CircuitOpen, s.breaker.currentState())
+}
+
+func (s *CircuitBreakerTestSuite) TestOpensAfterConsecutiveFailures() {
+	s.send(outcomeUnhealthy)
+	s.send(outcomeUnhealthy)
+	s.send(outcomeHealthy) // Resets the count.
+	s.send(outcomeUnhealthy)
+	s.send(outcomeUnhealthy)
+	s.Equal(CircuitClosed, s.breaker.currentState())
+
+	s.send(outcomeUnhealthy)
+
+	s.Equal(CircuitOpen, s.breaker.currentState())
+	s.Equal([]CircuitState{CircuitOpen}, s.transitions)
+}
+
+func (s *CircuitBreakerTestSuite) TestUnknownOutcomesDoNotCount() {
+	s.send(outcomeUnhealthy)
+	s.send(outcomeUnhealthy)
+	s.send(outcomeUnknown)
+	s.send(outcomeUnknown)
+
+	s.Equal(CircuitClosed, s.breaker.currentState(), "unknown outcomes should neither count nor reset failures")
+	s.send(outcomeUnhealthy)
+	s.Equal(CircuitOpen, s.breaker.currentState())
+}
+
+func (s *CircuitBreakerTestSuite) TestOpenRejectsWithRetryHint() {
+	s.trip()
+	s.now = s.now.Add(10 * time.Second)
+
+	err := s.breaker.allow()
+
+	var circuitErr *CircuitOpenError
+	s.Require().ErrorAs(err, &circuitErr)
+	s.ErrorIs(err, ErrCircuitOpen)
+	s.Equal(20*time.Second, circuitErr.RetryAfter, "should be retried once the open timeout has passed")
+}
+
+func (s *CircuitBreakerTestSuite) TestHalfOpenLetsOneTrialThrough() {
+	s.trip()
+	s.now = s.now.Add(30 * time.Second)
+
+	s.Require().NoError(s.breaker.allow())
+	s.Equal(CircuitHalfOpen, s.breaker.currentState())
+
+	err := s.breaker.allow()
+	var circuitErr *CircuitOpenError
+	s.Require().ErrorAs(err, &circuitErr, "a second request should wait for the trial")
+	s.Equal(30*time.Second, circuitErr.RetryAfter)
+}
+
+func (s *CircuitBreakerTestSuite) TestHalfOpenClosesAfterSuccesses() {
+	s.trip()
+	s.now = s.now.Add(30 * time.Second)
+
+	s.send(outcomeHealthy)
+	s.Equal(CircuitHalfOpen, s.breaker.currentState())
+	s.send(outcomeUnknown) // Frees the trial without counting.
+	s.Equal(CircuitHalfOpen, s.breaker.currentState())
+	s.send(outcomeHealthy)
+
+	s.Equal(CircuitClosed, s.breaker.currentState())
+	s.Equal([]CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}, s.transitions)
+
+	// The failure count starts again once closed.
+	s.send(outcomeUnhealthy)
+	s.send(outcomeUnhealthy)
+	s.Equal(CircuitClosed, s.breaker.currentState())
+}
+
+func (s *CircuitBreakerTestSuite) TestHalfOpenReopensOnFailure() {
+	s.trip()
+	s.now = s.now.Add(30 * time.Second)
+
+	s.send(outcomeHealthy)
+	s.send(outcomeUnhealthy)
+
+	s.Equal(CircuitOpen, s.breaker.currentState())
+	err := s.breaker.allow()
+	var circuitErr *CircuitOpenError
+	s.Require().ErrorAs(err, &circuitErr)
+	s.Equal(30*time.Second, circuitErr.RetryAfter, "the open timeout should start again")
+}
+
+func (s *CircuitBreakerTestSuite) TestDisabled() {
+	breaker := newCircuitBreaker(CircuitBreakerConfig{}, nil)
+
+	for range 10 {
+		s.Require().NoError(breaker.allow())
+		breaker.record(outcomeUnhealthy)
+	}
+	s.Equal(CircuitClosed, breaker.currentState())
+}
+
+func (s *CircuitBreakerTestSuite) TestClient_CountsOnlyServiceFailures() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL,
+		WithCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1}, nil),
+		WithRateLimit(RateLimitConfig{RequestsPerSecond: 0.001, Burst: 1}),
+	)
+
+	// Uses the only token; a rejected product means the service is up.
+	_, err := client.CheckInventory(context.Background(), uuid.New(), 1)
+	s.Require().ErrorIs(err, ErrInvalidProduct)
+
+	// Throttled by the limiter, so never sent.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = client.CheckInventory(ctx, uuid.New(), 1)
+	s.Require().ErrorContains(err, "rate limit")
+	s.Equal(CircuitClosed, client.CircuitState(), "throttled requests should not open the breaker")
+}
+
+func (s *CircuitBreakerTestSuite) TestClient_IgnoresCancelledRequests() {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(server.URL, WithCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1}, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err := client.CheckInventory(ctx, uuid.New(), 1)
+
+	s.Require().ErrorIs(err, context.Canceled)
+	s.Equal(CircuitClosed, client.CircuitState(), "cancelled requests should not open the breaker")
+}
+
+func (s *CircuitBreakerTestSuite) TestClient_OpensOnUnavailableService() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1}, nil))
+
+	_, err := client.CheckInventory(context.Background(), uuid.New(), 1)
+	s.Require().ErrorIs(err, ErrUnavailable)
+	s.Equal(CircuitOpen, client.CircuitState())
+
+	_, err = client.CheckInventory(context.Background(), uuid.New(), 1)
+	s.ErrorIs(err, ErrCircuitOpen)
+}
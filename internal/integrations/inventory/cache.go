@@ -0,0 +1,267 @@
+package inventory
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultCacheTTL        = 5 * time.Second
+	defaultCacheMaxEntries = 10000
+)
+
+type CacheConfig struct {
+	// Enabled turns the cache on. It is off by default because a cached
+	// positive result may be stale: the stock may have been sold since.
+	Enabled bool `yaml:"enabled"`
+	// TTL is how long a result is cached. Defaults to defaultCacheTTL.
+	TTL time.Duration `yaml:"ttl"`
+	// MaxEntries is how many results are cached before the least recently
+	// used is evicted. Defaults to defaultCacheMaxEntries.
+	MaxEntries int `yaml:"maxEntries" validate:"omitempty,min=1"`
+	// QuantityBucket groups requested quantities so that checks for similar
+	// quantities share a result. A quantity is checked as the top of its
+	// bucket, so a cached positive result holds for every quantity in it.
+	// Defaults to 1, which caches every quantity separately.
+	QuantityBucket int32 `yaml:"quantityBucket" validate:"omitempty,min=1"`
+}
+
+// AvailabilityChecker checks whether a quantity of a product is in stock. It
+// is implemented by Client.
+type AvailabilityChecker interface {
+	CheckInventory(ctx context.Context, productID uuid.UUID, quantity int32) (bool, error)
+}
+
+type batchAvailabilityChecker interface {
+	CheckInventoryBatch(ctx context.Context, items map[uuid.UUID]int32) (map[uuid.UUID]bool, error)
+}
+
+// CacheStats counts lookups served by a CachingChecker.
+type CacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// CachingChecker caches the results of an AvailabilityChecker for a short
+// time. Concurrent lookups of the same product and quantity bucket share one
+// request. Errors are not cached.
+type CachingChecker struct {
+	checker AvailabilityChecker
+	cfg     CacheConfig
+	now     func() time.Time
+	group   singleflight.Group
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+
+	mu      sync.Mutex
+	entries map[cacheKey]*list.Element
+	lru     *list.List
+}
+
+type cacheKey struct {
+	productID uuid.UUID
+	quantity  int32
+}
+
+type cacheEntry struct {
+	key       cacheKey
+	available bool
+	expiresAt time.Time
+}
+
+func NewCachingChecker(checker AvailabilityChecker, cfg CacheConfig) *CachingChecker {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultCacheTTL
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = defaultCacheMaxEntries
+	}
+	if cfg.QuantityBucket <= 0 {
+		cfg.QuantityBucket = 1
+	}
+	return &CachingChecker{
+		checker: checker,
+		cfg:     cfg,
+		now:     time.Now,
+		entries: make(map[cacheKey]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// CheckInventory returns the cached result for the product and quantity
+// bucket, or checks it with the underlying checker.
+func (c *CachingChecker) CheckInventory(ctx context.Context, productID uuid.UUID, quantity int32) (bool, error) {
+	key := c.key(productID, quantity)
+	if available, ok := c.get(key); ok {
+		c.hits.Add(1)
+		return c.exact(ctx, productID, quantity, key, available)
+	}
+	c.misses.Add(1)
+
+	// The shared request must not be cancelled because the caller that
+	// started it gave up; the others are still waiting for it.
+	ch := c.group.DoChan(fmt.Sprintf("%s/%d", key.productID, key.quantity), func() (any, error) {
+		available, err := c.checker.CheckInventory(context.WithoutCancel(ctx), key.productID, key.quantity)
+		if err != nil {
+			return false, err
+		}
+		c.put(key, available)
+		return available, nil
+	})
+
+	var available bool
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return false, res.Err
+		}
+		available = res.Val.(bool)
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+
+	return c.exact(ctx, productID, quantity, key, available)
+}
+
+// CheckInventoryBatch returns the cached results and checks the rest in one
+// batch if the underlying checker supports it, or one by one otherwise.
+func (c *CachingChecker) CheckInventoryBatch(ctx context.Context, items map[uuid.UUID]int32) (map[uuid.UUID]bool, error) {
+	batch, ok := c.checker.(batchAvailabilityChecker)
+	if !ok {
+		available := make(map[uuid.UUID]bool, len(items))
+		for productID, quantity := range items {
+			ok, err := c.CheckInventory(ctx, productID, quantity)
+			if err != nil {
+				return nil, fmt.Errorf("product %s: %w", productID, err)
+			}
+			available[productID] = ok
+		}
+		return available, nil
+	}
+
+	available := make(map[uuid.UUID]bool, len(items))
+	misses := make(map[uuid.UUID]int32)
+	for productID, quantity := range items {
+		key := c.key(productID, quantity)
+		if ok, cached := c.get(key); cached {
+			c.hits.Add(1)
+			available[productID] = ok
+			continue
+		}
+		c.misses.Add(1)
+		misses[productID] = key.quantity
+	}
+
+	if len(misses) > 0 {
+		results, err := batch.CheckInventoryBatch(ctx, misses)
+		if err != nil {
+			return nil, err
+		}
+		for productID, bucketQuantity := range misses {
+			c.put(cacheKey{productID: productID, quantity: bucketQuantity}, results[productID])
+			available[productID] = results[productID]
+		}
+	}
+
+	// Products that are short of the top of their bucket may still have the
+	// requested quantity; check those exactly without caching the result.
+	recheck := make(map[uuid.UUID]int32)
+	for productID, quantity := range items {
+		if !available[productID] && quantity < c.key(productID, quantity).quantity {
+			recheck[productID] = quantity
+		}
+	}
+	if len(recheck) == 0 {
+		return available, nil
+	}
+
+	results, err := batch.CheckInventoryBatch(ctx, recheck)
+	if err != nil {
+		return nil, err
+	}
+	for productID := range recheck {
+		available[productID] = results[productID]
+	}
+
+	return available, nil
+}
+
+// Stats returns the number of cache hits and misses so far and the number of
+// cached results.
+func (c *CachingChecker) Stats() CacheStats {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+
+	return CacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: entries,
+	}
+}
+
+// key rounds the quantity up to the top of its bucket.
+func (c *CachingChecker) key(productID uuid.UUID, quantity int32) cacheKey {
+	bucket := c.cfg.QuantityBucket
+	if quantity > 0 && bucket > 1 {
+		quantity = (quantity + bucket - 1) / bucket * bucket
+	}
+	return cacheKey{productID: productID, quantity: quantity}
+}
+
+// exact checks the requested quantity itself if the top of its bucket is not
+// available. The result is not cached as it only holds for that quantity.
+func (c *CachingChecker) exact(ctx context.Context, productID uuid.UUID, quantity int32, key cacheKey, available bool) (bool, error) {
+	if available || quantity >= key.quantity {
+		return available, nil
+	}
+	return c.checker.CheckInventory(ctx, productID, quantity)
+}
+
+func (c *CachingChecker) get(key cacheKey) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return false, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		return false, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.available, true
+}
+
+func (c *CachingChecker) put(key cacheKey, available bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.cfg.TTL)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.available = available
+		entry.expiresAt = expiresAt
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, available: available, expiresAt: expiresAt})
+	for c.lru.Len() > c.cfg.MaxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,242 @@
+package inventory
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestCachingChecker(t *testing.T) {
+	suite.Run(t, new(CachingCheckerTestSuite))
+}
+
+type CachingCheckerTestSuite struct {
+	suite.Suite
+
+	now     time.Time
+	checker *stockChecker
+}
+
+func (s *CachingCheckerTestSuite) SetupTest() {
+	s.now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.checker = &stockChecker{stock: make(map[uuid.UUID]int32)}
+}
+
+// cache creates a CachingChecker in front of the suite's checker, using the
+// suite's clock.
+func (s *CachingCheckerTestSuite) cache(cfg CacheConfig) *CachingChecker {
+	c := NewCachingChecker(s.checker, cfg)
+	c.now = func() time.Time { return s.now }
+	return c
+}
+
+// check checks a product and fails the test on error.
+func (s *CachingCheckerTestSuite) check(c *CachingChecker, productID uuid.UUID, quantity int32) bool {
+	available, err := c.CheckInventory(context.Background(), productID, quantity)
+	s.Require().NoError(err)
+	return available
+}
+
+func (s *CachingCheckerTestSuite) TestCachesResults() {
+	productID := uuid.New()
+	s.checker.stock[productID] = 5
+	c := s.cache(CacheConfig{})
+
+	s.True(s.check(c, productID, 5))
+	s.checker.stock[productID] = 0
+	s.True(s.check(c, productID, 5), "the cached result should be returned")
+
+	s.Equal(1, s.checker.callCount())
+	s.Equal(CacheStats{Hits: 1, Misses: 1, Entries: 1}, c.Stats())
+}
+
+func (s *CachingCheckerTestSuite) TestExpiresAfterTTL() {
+	productID := uuid.New()
+	s.checker.stock[productID] = 5
+	c := s.cache(CacheConfig{TTL: 10 * time.Second})
+	s.Require().True(s.check(c, productID, 5))
+	s.checker.stock[productID] = 0
+
+	s.now = s.now.Add(10*time.Second - time.Nanosecond)
+	s.True(s.check(c, productID, 5), "the result should be cached until the TTL has passed")
+	s.now = s.now.Add(time.Nanosecond)
+	s.False(s.check(c, productID, 5), "an expired result should be checked again")
+
+	s.Equal(2, s.checker.callCount())
+	s.Equal(CacheStats{Hits: 1, Misses: 2, Entries: 1}, c.Stats())
+}
+
+func (s *CachingCheckerTestSuite) TestEvictsLeastRecentlyUsed() {
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+	c := s.cache(CacheConfig{MaxEntries: 2})
+	s.check(c, first, 1)
+	s.check(c, second, 1)
+	s.check(c, first, 1) // Makes second the least recently used.
+
+	s.check(c, third, 1)
+	s.Equal(3, s.checker.callCount())
+	s.Equal(2, c.Stats().Entries)
+
+	s.check(c, first, 1)
+	s.Equal(3, s.checker.callCount(), "the recently used result should still be cached")
+	s.check(c, second, 1)
+	s.Equal(4, s.checker.callCount(), "the least recently used result should have been evicted")
+}
+
+func (s *CachingCheckerTestSuite) TestCollapsesConcurrentLookups() {
+	productID := uuid.New()
+	s.checker.stock[productID] = 5
+	s.checker.release = make(chan struct{})
+	c := s.cache(CacheConfig{})
+
+	var wg sync.WaitGroup
+	results := make(chan bool, 5)
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			available, err := c.CheckInventory(context.Background(), productID, 5)
+			s.NoError(err)
+			results <- available
+		}()
+	}
+	// Let every lookup join the request in flight before it completes.
+	s.Require().Eventually(func() bool { return c.Stats().Misses == 5 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(s.checker.release)
+	wg.Wait()
+	close(results)
+
+	for available := range results {
+		s.True(available)
+	}
+	s.Equal(1, s.checker.callCount())
+}
+
+func (s *CachingCheckerTestSuite) TestSharedLookupOutlivesCaller() {
+	productID := uuid.New()
+	s.checker.stock[productID] = 5
+	s.checker.release = make(chan struct{})
+	c := s.cache(CacheConfig{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.CheckInventory(ctx, productID, 5)
+	s.Require().ErrorIs(err, context.Canceled)
+
+	close(s.checker.release)
+	s.Eventually(func() bool { return c.Stats().Entries == 1 }, time.Second, time.Millisecond,
+		"the shared request should complete and be cached after its caller gave up")
+}
+
+func (s *CachingCheckerTestSuite) TestQuantityBuckets() {
+	plenty, few := uuid.New(), uuid.New()
+	s.checker.stock[plenty] = 50
+	s.checker.stock[few] = 5
+	c := s.cache(CacheConfig{QuantityBucket: 10})
+
+	// A quantity is checked as the top of its bucket and the result shared
+	// by the whole bucket.
+	s.True(s.check(c, plenty, 3))
+	s.True(s.check(c, plenty, 7))
+	s.Equal([]checkCall{{plenty, 10}}, s.checker.callList())
+
+	// If the top of the bucket is not available the requested quantity is
+	// checked exactly, and only the bucket's result is cached.
+	s.True(s.check(c, few, 3))
+	s.True(s.check(c, few, 4))
+	s.Equal([]checkCall{{plenty, 10}, {few, 10}, {few, 3}, {few, 4}}, s.checker.callList())
+	s.Equal(CacheStats{Hits: 2, Misses: 2, Entries: 2}, c.Stats())
+}
+
+func (s *CachingCheckerTestSuite) TestDoesNotCacheErrors() {
+	productID := uuid.New()
+	s.checker.err = ErrUnavailable
+	c := s.cache(CacheConfig{})
+
+	_, err := c.CheckInventory(context.Background(), productID, 1)
+	s.Require().ErrorIs(err, ErrUnavailable)
+	s.checker.err = nil
+	s.False(s.check(c, productID, 1))
+
+	s.Equal(2, s.checker.callCount())
+	s.Equal(CacheStats{Misses: 2, Entries: 1}, c.Stats())
+}
+
+func (s *CachingCheckerTestSuite) TestCheckInventoryBatch() {
+	cached, uncached := uuid.New(), uuid.New()
+	s.checker.stock[cached] = 5
+	s.checker.stock[uncached] = 5
+	c := s.cache(CacheConfig{})
+	s.Require().True(s.check(c, cached, 1))
+
+	available, err := c.CheckInventoryBatch(context.Background(), map[uuid.UUID]int32{cached: 1, uncached: 2})
+
+	s.Require().NoError(err)
+	s.Equal(map[uuid.UUID]bool{cached: true, uncached: true}, available)
+	s.Equal([]map[uuid.UUID]int32{{uncached: 2}}, s.checker.batches, "only the uncached product should be checked")
+	s.Equal(CacheStats{Hits: 1, Misses: 2, Entries: 2}, c.Stats())
+}
+
+// stockChecker is an AvailabilityChecker backed by a stock level per product.
+// It records every check it receives.
+type stockChecker struct {
+	stock map[uuid.UUID]int32
+	// err, if set, is returned by every check.
+	err error
+	// release, if set, holds every check until it is closed.
+	release chan struct{}
+
+	mu      sync.Mutex
+	calls   []checkCall
+	batches []map[uuid.UUID]int32
+}
+
+type checkCall struct {
+	productID uuid.UUID
+	quantity  int32
+}
+
+func (c *stockChecker) CheckInventory(ctx context.Context, productID uuid.UUID, quantity int32) (bool, error) {
+	if c.release != nil {
+		<-c.release
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, checkCall{productID: productID, quantity: quantity})
+	if c.err != nil {
+		return false, c.err
+	}
+	return c.stock[productID] >= quantity, nil
+}
+
+func (c *stockChecker) CheckInventoryBatch(ctx context.Context, items map[uuid.UUID]int32) (map[uuid.UUID]bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batches = append(c.batches, items)
+	if c.err != nil {
+		return nil, c.err
+	}
+	available := make(map[uuid.UUID]bool, len(items))
+	for productID, quantity := range items {
+		available[productID] = c.stock[productID] >= quantity
+	}
+	return available, nil
+}
+
+func (c *stockChecker) callCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.calls)
+}
+
+func (c *stockChecker) callList() []checkCall {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]checkCall(nil), c.calls...)
+}
@@ -4,32 +4,149 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/time/rate"
 )
 
+// defaultCheckConcurrency is how many products are checked at once when the
+// inventory service has no batch endpoint.
+const defaultCheckConcurrency = 8
+
 type Config struct {
+	// BaseURL is the inventory HTTP API. Reservations always use it, and
+	// availability checks do unless Transport is TransportGRPC.
+	BaseURL string `yaml:"baseUrl" validate:"required,http_url"`
+	// Transport selects how availability is checked: TransportHTTP (the
+	// default) or TransportGRPC.
+	Transport string `yaml:"transport" validate:"omitempty,oneof=http grpc"`
+	// GRPC configures the gRPC transport.
+	GRPC GRPCConfig `yaml:"grpc"`
+	// CheckConcurrency limits concurrent checks when the batch endpoint is
+	// unavailable. Defaults to defaultCheckConcurrency.
+	CheckConcurrency int `yaml:"checkConcurrency" validate:"omitempty,min=1"`
+	// CircuitBreaker stops requests to an inventory service that keeps
+	// failing. Disabled by default.
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuitBreaker"`
+	// RateLimit caps the rate of requests sent by this client. Disabled by
+	// default.
+	RateLimit RateLimitConfig `yaml:"rateLimit"`
+	// Auth configures the credentials sent with every request. Requests are
+	// not authenticated by default.
+	Auth AuthConfig `yaml:"auth"`
+	// Cache caches availability results for a short time. Disabled by
+	// default.
+	Cache CacheConfig `yaml:"cache"`
+	// Warehouses are the fulfilment locations orders are routed to, each
+	// with its own inventory HTTP API. Orders are checked and reserved with
+	// BaseURL alone if there are none.
+	Warehouses []WarehouseConfig `yaml:"warehouses" validate:"dive"`
+	// Routing picks the warehouse each line item is fulfilled from:
+	// "priority" (the default), "nearest" or "fewest-splits".
+	Routing string `yaml:"routing" validate:"omitempty,oneof=priority nearest fewest-splits"`
+}
+
+// WarehouseConfig configures a warehouse's inventory service. The client
+// settings in Config, such as Auth and CircuitBreaker, apply to every
+// warehouse.
+type WarehouseConfig struct {
+	Name    string `yaml:"name" validate:"required"`
 	BaseURL string `yaml:"baseUrl" validate:"required,http_url"`
+	// Priority ranks warehouses, lowest first.
+	Priority int `yaml:"priority"`
+	// Country is the ISO 3166-1 alpha-2 code of the country the warehouse is
+	// in, and Region the state or province.
+	Country string `yaml:"country"`
+	Region  string `yaml:"region"`
+}
+
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained request rate. Zero disables rate
+	// limiting.
+	RequestsPerSecond float64 `yaml:"requestsPerSecond" validate:"omitempty,gt=0"`
+	// Burst is how many requests may be sent at once above the sustained
+	// rate. Defaults to 1.
+	Burst int `yaml:"burst" validate:"omitempty,min=1"`
 }
 
 type Client struct {
-	baseURL    string
-	httpClient *http.Client
+	baseURL          string
+	httpClient       *http.Client
+	checkConcurrency int
+	batch            batchSupport
+	breaker          *circuitBreaker
+	limiter          *rate.Limiter
+	auth             Authenticator
+}
+
+// ClientOption configures optional behaviour of the Client.
+type ClientOption func(*Client)
+
+// WithCheckConcurrency sets how many products are checked at once when the
+// inventory service has no batch endpoint. Values below one are ignored.
+func WithCheckConcurrency(n int) ClientOption {
+	return func(c *Client) {
+		if n > 0 {
+			c.checkConcurrency = n
+		}
+	}
 }
 
-func NewClient(baseURL string) *Client {
-	return &Client{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+// WithCircuitBreaker enables the circuit breaker. onChange, if not nil, is
+// called with every state change, e.g. to update a metric; it must not call
+// the Client.
+func WithCircuitBreaker(cfg CircuitBreakerConfig, onChange func(from, to CircuitState)) ClientOption {
+	return func(c *Client) {
+		c.breaker = newCircuitBreaker(cfg, onChange)
 	}
 }
 
+// WithRateLimit limits the rate of requests using a token bucket. Requests
+// over the limit wait for a token.
+func WithRateLimit(cfg RateLimitConfig) ClientOption {
+	return func(c *Client) {
+		if cfg.RequestsPerSecond <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), max(cfg.Burst, 1))
+	}
+}
+
+// WithAuthenticator authenticates every request with auth. A nil auth sends
+// requests without credentials.
+func WithAuthenticator(auth Authenticator) ClientOption {
+	return func(c *Client) {
+		c.auth = auth
+	}
+}
+
+func NewClient(baseURL string, opts ...ClientOption) *Client {
+	c := applyOptions(opts)
+	c.baseURL = baseURL
+	c.httpClient = &http.Client{
+		Timeout: 10 * time.Second,
+	}
+	return c
+}
+
+// applyOptions returns a Client with the defaults and opts applied, from
+// which both transports take their settings.
+func applyOptions(opts []ClientOption) *Client {
+	c := &Client{
+		checkConcurrency: defaultCheckConcurrency,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
 type CheckInventoryRequest struct {
 	ProductID uuid.UUID `json:"product_id"`
 	Quantity  int32     `json:"quantity"`
@@ -47,51 +164,165 @@ func (c *Client) CheckInventory(ctx context.Context, productID uuid.UUID, quanti
 		Quantity:  quantity,
 	}
 
-	body, err := json.Marshal(req)
+	resp, err := c.post(ctx, "/inventory/check", req)
 	if err != nil {
-		return false, fmt.Errorf("failed to marshal request: %w", err)
+		return false, err
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/inventory/check", bytes.NewBuffer(body))
+	// Handle different status codes
+	switch resp.statusCode {
+	case http.StatusOK:
+		var checkResp CheckInventoryResponse
+		if err := json.Unmarshal(resp.body, &checkResp); err != nil {
+			return false, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		return checkResp.Available, nil
+
+	default:
+		// A 400 means the product is invalid and is not worth retrying; see
+		// statusError for the other status codes.
+		var checkResp CheckInventoryResponse
+		_ = json.Unmarshal(resp.body, &checkResp)
+		return false, statusError(resp, checkResp.Message, ErrInvalidProduct)
+	}
+}
+
+// CircuitState returns the current state of the circuit breaker. It is always
+// CircuitClosed if the breaker is disabled.
+func (c *Client) CircuitState() CircuitState {
+	return c.breaker.currentState()
+}
+
+// response is a response from the inventory service with its body read.
+type response struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+	// authorization is the Authorization header the request was sent with.
+	authorization string
+}
+
+// post sends payload as JSON to the given path and returns the response.
+func (c *Client) post(ctx context.Context, path string, payload any) (*response, error) {
+	return c.postWithHeader(ctx, path, nil, payload)
+}
+
+// postWithHeader is post with additional request headers.
+func (c *Client) postWithHeader(ctx context.Context, path string, header http.Header, payload any) (*response, error) {
+	if err := acquire(ctx, c.limiter, c.breaker); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.send(ctx, path, header, payload)
+
+	c.breaker.record(requestOutcome(ctx, resp, err))
+
+	return resp, err
+}
+
+// acquire waits for the rate limiter and then asks the circuit breaker to let
+// a request through; either may be nil. The limiter comes first so that
+// requests throttled by the client, which never reach the inventory service,
+// cannot open the breaker. Every acquired request must be followed by a call
+// to breaker.record.
+func acquire(ctx context.Context, limiter *rate.Limiter, breaker *circuitBreaker) error {
+	if limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return fmt.Errorf("rate limit: %w", err)
+		}
+	}
+
+	return breaker.allow()
+}
+
+// requestOutcome tells the breaker what a request says about the health of the
+// inventory service. Only transport errors and 5xx or 429 responses count
+// towards opening it, apart from 501, which is the service answering that it
+// lacks an endpoint. A rejected request, or rejected credentials, also mean it
+// is responding, and a request the caller cancelled says nothing either way.
+func requestOutcome(ctx context.Context, resp *response, err error) outcome {
 	if err != nil {
-		return false, fmt.Errorf("failed to create request: %w", err)
+		var transportErr *transportError
+		if ctx.Err() == nil && errors.As(err, &transportErr) {
+			return outcomeUnhealthy
+		}
+		return outcomeUnknown
+	}
+	if resp.statusCode == http.StatusNotImplemented {
+		return outcomeHealthy
+	}
+	if resp.statusCode >= http.StatusInternalServerError || resp.statusCode == http.StatusTooManyRequests {
+		return outcomeUnhealthy
 	}
+	return outcomeHealthy
+}
 
-	httpReq.Header.Set("Content-Type", "application/json")
+// transportError is a request that failed to reach the inventory service or
+// to read its response, e.g. because the connection was refused or timed out.
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
 
-	resp, err := c.httpClient.Do(httpReq)
+func (c *Client) send(ctx context.Context, path string, header http.Header, payload any) (*response, error) {
+	body, err := json.Marshal(payload)
 	if err != nil {
-		return false, fmt.Errorf("failed to make request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	resp, err := c.do(ctx, path, header, body)
 	if err != nil {
-		return false, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
-	// Handle different status codes
-	switch resp.StatusCode {
-	case http.StatusOK:
-		var checkResp CheckInventoryResponse
-		if err := json.Unmarshal(respBody, &checkResp); err != nil {
-			return false, fmt.Errorf("failed to unmarshal response: %w", err)
-		}
-		return checkResp.Available, nil
+	// A cached token may have been revoked before it expired. Fetch a new
+	// one and try once more.
+	if invalidator, ok := c.auth.(tokenInvalidator); ok && resp.statusCode == http.StatusUnauthorized {
+		invalidator.invalidateToken(resp.authorization)
+		return c.do(ctx, path, header, body)
+	}
 
-	case http.StatusBadRequest:
-		// Non-retryable error (e.g., invalid product)
-		var checkResp CheckInventoryResponse
-		if err := json.Unmarshal(respBody, &checkResp); err != nil {
-			return false, fmt.Errorf("non-retryable error: invalid request")
+	return resp, nil
+}
+
+func (c *Client) do(ctx context.Context, path string, header http.Header, body []byte) (*response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for name, values := range header {
+		httpReq.Header[name] = values
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.auth != nil {
+		if err := c.auth.Authenticate(ctx, httpReq); err != nil {
+			return nil, fmt.Errorf("failed to authenticate request: %w", err)
 		}
-		return false, fmt.Errorf("non-retryable error: %s", checkResp.Message)
+	}
 
-	case http.StatusServiceUnavailable, http.StatusInternalServerError:
-		// Retryable error (service temporarily unavailable)
-		return false, fmt.Errorf("retryable error: service unavailable (status: %d)", resp.StatusCode)
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, &transportError{fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer httpResp.Body.Close()
 
-	default:
-		return false, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, &transportError{fmt.Errorf("failed to read response body: %w", err)}
 	}
+
+	return &response{
+		statusCode:    httpResp.StatusCode,
+		header:        httpResp.Header,
+		body:          respBody,
+		authorization: httpReq.Header.Get("Authorization"),
+	}, nil
 }
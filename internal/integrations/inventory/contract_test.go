@@ -0,0 +1,64 @@
+package inventory_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/inventory"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/inventory/inventorytest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestClient_Contract(t *testing.T) {
+	inventorytest.RunContract(t, newHTTPChecker)
+}
+
+func TestGRPCClient_Contract(t *testing.T) {
+	inventorytest.RunContract(t, func(t *testing.T, scenario inventorytest.Scenario) inventory.AvailabilityChecker {
+		server := inventorytest.NewGRPCServer()
+		t.Cleanup(server.Close)
+
+		server.SetDefaultStock(1000)
+		switch scenario {
+		case inventorytest.ScenarioIntermittent:
+			server.FailNext(status.Error(codes.Unavailable, "Service temporarily unavailable"))
+		case inventorytest.ScenarioNonRetryable:
+			server.FailNext(status.Error(codes.InvalidArgument, "Invalid product ID or quantity"))
+		case inventorytest.ScenarioRateLimited:
+			st, err := status.New(codes.ResourceExhausted, "Too many requests").
+				WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(5 * time.Second)})
+			require.NoError(t, err)
+			server.FailNext(st.Err())
+		case inventorytest.ScenarioUnauthorized:
+			server.FailNext(status.Error(codes.Unauthenticated, "Missing credentials"))
+		case inventorytest.ScenarioSlow:
+			server.SetDelay(inventorytest.SlowResponseDelay)
+		case inventorytest.ScenarioMalformed:
+			// Protobuf responses cannot be malformed JSON.
+			return nil
+		}
+
+		conn, err := server.Dial()
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+
+		return inventory.NewGRPCClient(conn)
+	})
+}
+
+func TestCachingChecker_Contract(t *testing.T) {
+	inventorytest.RunContract(t, func(t *testing.T, scenario inventorytest.Scenario) inventory.AvailabilityChecker {
+		return inventory.NewCachingChecker(newHTTPChecker(t, scenario), inventory.CacheConfig{Enabled: true})
+	})
+}
+
+func newHTTPChecker(t *testing.T, scenario inventorytest.Scenario) inventory.AvailabilityChecker {
+	server := inventorytest.NewHTTPServer(scenario)
+	t.Cleanup(server.Close)
+
+	return inventory.NewClient(server.URL)
+}
@@ -0,0 +1,118 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors returned by the Client, wrapped in a *StatusError. Match them with
+// errors.Is.
+var (
+	// ErrInvalidProduct means the inventory service rejected a product or
+	// quantity. Retrying the same request will not succeed.
+	ErrInvalidProduct = errors.New("invalid product")
+	// ErrInvalidRequest means the inventory service rejected a reservation
+	// or restock request. Retrying the same request will not succeed.
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrReservationNotFound means the reservation has expired or was
+	// already released.
+	ErrReservationNotFound = errors.New("reservation not found")
+	// ErrUnavailable means the inventory service failed to handle the
+	// request and it may succeed if retried.
+	ErrUnavailable = errors.New("inventory service unavailable")
+	// ErrRateLimited means the inventory service rejected the request
+	// because too many requests were sent. It may succeed if retried later.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrUnauthorized means the inventory service, or the OAuth2 token
+	// endpoint, rejected the client's credentials. Retrying will not succeed
+	// until the credentials are fixed.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrUnexpectedStatus means the inventory service responded with a status
+	// code the client does not handle.
+	ErrUnexpectedStatus = errors.New("unexpected status code")
+)
+
+// StatusError is returned when the inventory service responds with an error
+// status code.
+type StatusError struct {
+	StatusCode int
+	// Message is the explanation given by the inventory service, if any.
+	Message string
+	// Err is the sentinel error the status code maps to.
+	Err error
+	// RetryAfter is how long the inventory service asked the client to wait
+	// before retrying, from the Retry-After header. Zero if it did not say.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s (status: %d)", e.Err, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s (status: %d)", e.Err, e.Message, e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether a request that failed with err may succeed if it
+// is sent again. Errors that did not come from an inventory service response,
+// such as timeouts or an open circuit breaker, are retryable.
+func Retryable(err error) bool {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return true
+	}
+	return errors.Is(err, ErrUnavailable) || errors.Is(err, ErrRateLimited)
+}
+
+// statusError builds the error for a response status code that the calling
+// method does not treat as success. invalid is the sentinel used for 400 Bad
+// Request.
+func statusError(resp *response, message string, invalid error) error {
+	err := &StatusError{StatusCode: resp.statusCode, Message: message}
+	switch resp.statusCode {
+	case http.StatusBadRequest:
+		err.Err = invalid
+	case http.StatusUnauthorized, http.StatusForbidden:
+		err.Err = ErrUnauthorized
+	case http.StatusTooManyRequests:
+		err.Err = ErrRateLimited
+		err.RetryAfter = retryAfter(resp.header, time.Now())
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		err.Err = ErrUnavailable
+		err.RetryAfter = retryAfter(resp.header, time.Now())
+	default:
+		err.Err = ErrUnexpectedStatus
+	}
+	return err
+}
+
+// retryAfter parses the Retry-After header, which is either a number of
+// seconds or an HTTP date. It returns zero if the header is missing, invalid
+// or in the past.
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
@@ -0,0 +1,294 @@
+package inventory
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/inventory/inventorypb"
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// Transports accepted in Config.Transport.
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+// defaultGRPCTimeout bounds each call, like the HTTP client's timeout.
+const defaultGRPCTimeout = 10 * time.Second
+
+type GRPCConfig struct {
+	// Target is the address of the inventory gRPC service, e.g.
+	// "inventory:9090".
+	Target string `yaml:"target"`
+	// Insecure sends requests without TLS. Only meant for local development.
+	Insecure bool `yaml:"insecure"`
+}
+
+// GRPCClient checks inventory over gRPC. It returns the same errors as
+// Client, with each gRPC status code translated to its HTTP equivalent, and
+// is rate limited and guarded by a circuit breaker the same way.
+type GRPCClient struct {
+	client           inventorypb.InventoryServiceClient
+	conn             *grpc.ClientConn
+	timeout          time.Duration
+	checkConcurrency int
+	batch            batchSupport
+	breaker          *circuitBreaker
+	limiter          *rate.Limiter
+}
+
+// NewGRPCClient creates a GRPCClient that sends requests over conn. It takes
+// the same options as NewClient, except WithAuthenticator: conn must already
+// authenticate its requests.
+func NewGRPCClient(conn grpc.ClientConnInterface, opts ...ClientOption) *GRPCClient {
+	settings := applyOptions(opts)
+	return &GRPCClient{
+		client:           inventorypb.NewInventoryServiceClient(conn),
+		timeout:          defaultGRPCTimeout,
+		checkConcurrency: settings.checkConcurrency,
+		breaker:          settings.breaker,
+		limiter:          settings.limiter,
+	}
+}
+
+// DialGRPC creates a GRPCClient connected to cfg.Target with opts applied.
+// Requests are authenticated with the Authenticator given by
+// WithAuthenticator, if any. Close the client when done.
+func DialGRPC(cfg GRPCConfig, opts ...ClientOption) (*GRPCClient, error) {
+	if cfg.Target == "" {
+		return nil, errors.New("grpc target is required")
+	}
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})),
+	}
+	if cfg.Insecure {
+		dialOpts[0] = grpc.WithTransportCredentials(insecure.NewCredentials())
+	}
+	if auth := applyOptions(opts).auth; auth != nil {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(&perRPCAuth{auth: auth, secure: !cfg.Insecure}))
+	}
+
+	conn, err := grpc.NewClient(cfg.Target, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grpc connection: %w", err)
+	}
+
+	c := NewGRPCClient(conn, opts...)
+	c.conn = conn
+	return c, nil
+}
+
+// Close closes the connection created by DialGRPC.
+func (c *GRPCClient) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// CheckInventory checks if the requested quantity of a product is available in inventory
+func (c *GRPCClient) CheckInventory(ctx context.Context, productID uuid.UUID, quantity int32) (bool, error) {
+	if err := acquire(ctx, c.limiter, c.breaker); err != nil {
+		return false, err
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.CheckInventory(callCtx, &inventorypb.CheckInventoryRequest{
+		ProductId: productID.String(),
+		Quantity:  quantity,
+	})
+	c.breaker.record(callOutcome(ctx, err))
+	if err != nil {
+		return false, grpcError(err)
+	}
+
+	return resp.GetAvailable(), nil
+}
+
+// CheckInventoryBatch checks the requested quantity of every product in one
+// call. If the inventory service does not implement the batch method the
+// products are checked individually, at most checkConcurrency at a time, and
+// the batch method is not tried again for batchRecheckInterval.
+func (c *GRPCClient) CheckInventoryBatch(ctx context.Context, items map[uuid.UUID]int32) (map[uuid.UUID]bool, error) {
+	if c.batch.supported() {
+		available, err := c.checkInventoryBatch(ctx, items)
+		if status.Code(err) != codes.Unimplemented {
+			return available, err
+		}
+		c.batch.markUnsupported()
+	}
+
+	return checkInventoryConcurrently(ctx, c, items, c.checkConcurrency)
+}
+
+// checkInventoryBatch calls the batch method. Errors are returned as they are
+// so the caller can tell whether the method exists.
+func (c *GRPCClient) checkInventoryBatch(ctx context.Context, items map[uuid.UUID]int32) (map[uuid.UUID]bool, error) {
+	if err := acquire(ctx, c.limiter, c.breaker); err != nil {
+		return nil, err
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req := &inventorypb.CheckInventoryBatchRequest{
+		Items: make([]*inventorypb.CheckInventoryRequest, 0, len(items)),
+	}
+	for _, item := range reservationItems(items) {
+		req.Items = append(req.Items, &inventorypb.CheckInventoryRequest{
+			ProductId: item.ProductID.String(),
+			Quantity:  item.Quantity,
+		})
+	}
+
+	resp, err := c.client.CheckInventoryBatch(callCtx, req)
+	c.breaker.record(callOutcome(ctx, err))
+	if status.Code(err) == codes.Unimplemented {
+		return nil, err
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	available := make(map[uuid.UUID]bool, len(items))
+	for _, result := range resp.GetResults() {
+		productID, err := uuid.Parse(result.GetProductId())
+		if err != nil {
+			return nil, fmt.Errorf("invalid product id in batch response: %w", err)
+		}
+		available[productID] = result.GetAvailable()
+	}
+	for productID := range items {
+		if _, ok := available[productID]; !ok {
+			return nil, fmt.Errorf("incomplete batch response: no result for product %s", productID)
+		}
+	}
+
+	return available, nil
+}
+
+// CircuitState returns the current state of the circuit breaker. It is always
+// CircuitClosed if the breaker is disabled.
+func (c *GRPCClient) CircuitState() CircuitState {
+	return c.breaker.currentState()
+}
+
+// callOutcome tells the breaker what a call says about the health of the
+// inventory service, like requestOutcome does for HTTP. Only the codes
+// translated to a 5xx or 429 status count towards opening it, apart from
+// Unimplemented, which is the service answering that it lacks a method. A
+// call the caller cancelled says nothing either way.
+func callOutcome(ctx context.Context, err error) outcome {
+	if err == nil {
+		return outcomeHealthy
+	}
+	if ctx.Err() != nil {
+		return outcomeUnknown
+	}
+	switch code := status.Code(err); code {
+	case codes.Canceled:
+		return outcomeUnknown
+	case codes.Unimplemented:
+		return outcomeHealthy
+	default:
+		if statusCode := httpStatusFromCode(code); statusCode >= http.StatusInternalServerError || statusCode == http.StatusTooManyRequests {
+			return outcomeUnhealthy
+		}
+		return outcomeHealthy
+	}
+}
+
+// grpcError translates a gRPC status into the error Client returns for the
+// equivalent HTTP status code, so both transports are handled alike.
+func grpcError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	if st.Code() == codes.Canceled {
+		return fmt.Errorf("request cancelled: %w", err)
+	}
+
+	resp := &response{statusCode: httpStatusFromCode(st.Code())}
+	statusErr := statusError(resp, st.Message(), ErrInvalidProduct).(*StatusError)
+	if statusErr.Err == ErrRateLimited || statusErr.Err == ErrUnavailable {
+		for _, detail := range st.Details() {
+			if info, ok := detail.(*errdetails.RetryInfo); ok {
+				statusErr.RetryAfter = max(info.GetRetryDelay().AsDuration(), 0)
+			}
+		}
+	}
+	return statusErr
+}
+
+// httpStatusFromCode maps a gRPC status code to the HTTP status code with the
+// same meaning.
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// perRPCAuth sends the headers an Authenticator sets on an HTTP request as
+// gRPC metadata.
+type perRPCAuth struct {
+	auth   Authenticator
+	secure bool
+}
+
+func (a *perRPCAuth) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.auth.Authenticate(ctx, req); err != nil {
+		return nil, fmt.Errorf("failed to authenticate request: %w", err)
+	}
+
+	md := make(map[string]string, len(req.Header))
+	for name := range req.Header {
+		md[strings.ToLower(name)] = req.Header.Get(name)
+	}
+	return md, nil
+}
+
+func (a *perRPCAuth) RequireTransportSecurity() bool {
+	return a.secure
+}
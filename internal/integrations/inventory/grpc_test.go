@@ -0,0 +1,220 @@
+package inventory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/inventory"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/inventory/inventorytest"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestGRPCClient(t *testing.T) {
+	suite.Run(t, new(GRPCClientTestSuite))
+}
+
+type GRPCClientTestSuite struct {
+	suite.Suite
+
+	server *inventorytest.GRPCServer
+	conn   *grpc.ClientConn
+	client *inventory.GRPCClient
+}
+
+func (s *GRPCClientTestSuite) SetupTest() {
+	s.server = inventorytest.NewGRPCServer()
+
+	conn, err := s.server.Dial()
+	s.Require().NoError(err)
+	s.conn = conn
+	s.client = inventory.NewGRPCClient(conn)
+}
+
+func (s *GRPCClientTestSuite) TearDownTest() {
+	s.conn.Close()
+	s.server.Close()
+}
+
+func (s *GRPCClientTestSuite) TestCheckInventory() {
+	// Setup
+	productID := uuid.New()
+	s.server.SetStock(productID, 5)
+
+	// Invoke
+	inStock, err := s.client.CheckInventory(context.Background(), productID, 5)
+	s.Require().NoError(err)
+	outOfStock, err := s.client.CheckInventory(context.Background(), productID, 6)
+	s.Require().NoError(err)
+
+	// Assert
+	s.True(inStock)
+	s.False(outOfStock)
+}
+
+func (s *GRPCClientTestSuite) TestCheckInventory_Errors() {
+	retryInfo, err := status.New(codes.ResourceExhausted, "slow down").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(3 * time.Second)})
+	s.Require().NoError(err)
+
+	tests := []struct {
+		name       string
+		err        error
+		sentinel   error
+		retryable  bool
+		retryAfter time.Duration
+	}{
+		{
+			name:     "Invalid argument",
+			err:      status.Error(codes.InvalidArgument, "unknown product"),
+			sentinel: inventory.ErrInvalidProduct,
+		},
+		{
+			name:      "Unavailable",
+			err:       status.Error(codes.Unavailable, "try again"),
+			sentinel:  inventory.ErrUnavailable,
+			retryable: true,
+		},
+		{
+			name:       "Rate limited with retry info",
+			err:        retryInfo.Err(),
+			sentinel:   inventory.ErrRateLimited,
+			retryable:  true,
+			retryAfter: 3 * time.Second,
+		},
+		{
+			name:     "Unauthenticated",
+			err:      status.Error(codes.Unauthenticated, "missing token"),
+			sentinel: inventory.ErrUnauthorized,
+		},
+		{
+			name:     "Not found",
+			err:      status.Error(codes.NotFound, "no such method"),
+			sentinel: inventory.ErrUnexpectedStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			s.server.FailNext(tt.err)
+
+			// Invoke
+			_, err := s.client.CheckInventory(context.Background(), uuid.New(), 1)
+
+			// Assert
+			s.Require().ErrorIs(err, tt.sentinel)
+			s.Equal(tt.retryable, inventory.Retryable(err))
+
+			var statusErr *inventory.StatusError
+			s.Require().ErrorAs(err, &statusErr)
+			s.Equal(tt.retryAfter, statusErr.RetryAfter)
+		})
+	}
+}
+
+func (s *GRPCClientTestSuite) TestCheckInventoryBatch() {
+	// Setup
+	inStock, outOfStock := uuid.New(), uuid.New()
+	s.server.SetStock(inStock, 10)
+
+	// Invoke
+	available, err := s.client.CheckInventoryBatch(context.Background(), map[uuid.UUID]int32{
+		inStock:    2,
+		outOfStock: 1,
+	})
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal(map[uuid.UUID]bool{inStock: true, outOfStock: false}, available)
+	s.Equal(1, s.server.Calls())
+}
+
+func (s *GRPCClientTestSuite) TestCheckInventoryBatch_FallsBackWhenUnimplemented() {
+	// Setup
+	inStock, outOfStock := uuid.New(), uuid.New()
+	s.server.SetStock(inStock, 10)
+	s.server.SetBatchUnsupported(true)
+	items := map[uuid.UUID]int32{inStock: 2, outOfStock: 1}
+
+	// Invoke
+	available, err := s.client.CheckInventoryBatch(context.Background(), items)
+	s.Require().NoError(err)
+	_, err = s.client.CheckInventoryBatch(context.Background(), items)
+	s.Require().NoError(err)
+
+	// Assert
+	s.Equal(map[uuid.UUID]bool{inStock: true, outOfStock: false}, available)
+	// The batch method is not called again once it is known to be missing.
+	s.Equal(4, s.server.Calls())
+}
+
+func (s *GRPCClientTestSuite) TestCircuitBreaker() {
+	// Setup
+	client := inventory.NewGRPCClient(s.conn,
+		inventory.WithCircuitBreaker(inventory.CircuitBreakerConfig{FailureThreshold: 2}, nil),
+	)
+	s.server.FailNext(
+		status.Error(codes.InvalidArgument, "unknown product"),
+		status.Error(codes.Unavailable, "try again"),
+		status.Error(codes.Unavailable, "try again"),
+	)
+
+	// Invoke
+	_, err := client.CheckInventory(context.Background(), uuid.New(), 1)
+	s.Require().ErrorIs(err, inventory.ErrInvalidProduct)
+	s.Equal(inventory.CircuitClosed, client.CircuitState(), "a rejected product means the service is up")
+
+	for range 2 {
+		_, err = client.CheckInventory(context.Background(), uuid.New(), 1)
+		s.Require().ErrorIs(err, inventory.ErrUnavailable)
+	}
+	_, err = client.CheckInventory(context.Background(), uuid.New(), 1)
+
+	// Assert
+	s.Equal(inventory.CircuitOpen, client.CircuitState())
+	s.ErrorIs(err, inventory.ErrCircuitOpen)
+	s.Equal(3, s.server.Calls(), "requests should not be sent while the breaker is open")
+}
+
+func (s *GRPCClientTestSuite) TestRateLimit() {
+	// Setup
+	client := inventory.NewGRPCClient(s.conn,
+		inventory.WithCircuitBreaker(inventory.CircuitBreakerConfig{FailureThreshold: 1}, nil),
+		inventory.WithRateLimit(inventory.RateLimitConfig{RequestsPerSecond: 0.001, Burst: 1}),
+	)
+
+	// Invoke
+	_, err := client.CheckInventory(context.Background(), uuid.New(), 1)
+	s.Require().NoError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = client.CheckInventory(ctx, uuid.New(), 1)
+
+	// Assert
+	s.Require().ErrorContains(err, "rate limit")
+	s.Equal(1, s.server.Calls(), "throttled requests should not be sent")
+	s.Equal(inventory.CircuitClosed, client.CircuitState(), "throttled requests should not open the breaker")
+}
+
+func (s *GRPCClientTestSuite) TestCheckConcurrency() {
+	// Setup
+	client := inventory.NewGRPCClient(s.conn, inventory.WithCheckConcurrency(1))
+	s.server.SetBatchUnsupported(true)
+	s.server.SetDelay(20 * time.Millisecond)
+	items := map[uuid.UUID]int32{uuid.New(): 1, uuid.New(): 1, uuid.New(): 1}
+
+	// Invoke
+	start := time.Now()
+	_, err := client.CheckInventoryBatch(context.Background(), items)
+
+	// Assert
+	s.Require().NoError(err)
+	s.GreaterOrEqual(time.Since(start), 60*time.Millisecond, "products should be checked one at a time")
+}
@@ -0,0 +1,358 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: internal/integrations/inventory/inventorypb/inventory.proto
+
+package inventorypb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CheckInventoryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The product UUID in its canonical string form.
+	ProductId     string `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity      int32  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckInventoryRequest) Reset() {
+	*x = CheckInventoryRequest{}
+	mi := &file_internal_integrations_inventory_inventorypb_inventory_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckInventoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckInventoryRequest) ProtoMessage() {}
+
+func (x *CheckInventoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_integrations_inventory_inventorypb_inventory_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckInventoryRequest.ProtoReflect.Descriptor instead.
+func (*CheckInventoryRequest) Descriptor() ([]byte, []int) {
+	return file_internal_integrations_inventory_inventorypb_inventory_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CheckInventoryRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *CheckInventoryRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type CheckInventoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Available     bool                   `protobuf:"varint,1,opt,name=available,proto3" json:"available,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckInventoryResponse) Reset() {
+	*x = CheckInventoryResponse{}
+	mi := &file_internal_integrations_inventory_inventorypb_inventory_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckInventoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckInventoryResponse) ProtoMessage() {}
+
+func (x *CheckInventoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_integrations_inventory_inventorypb_inventory_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckInventoryResponse.ProtoReflect.Descriptor instead.
+func (*CheckInventoryResponse) Descriptor() ([]byte, []int) {
+	return file_internal_integrations_inventory_inventorypb_inventory_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CheckInventoryResponse) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+func (x *CheckInventoryResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type CheckInventoryBatchRequest struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Items         []*CheckInventoryRequest `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckInventoryBatchRequest) Reset() {
+	*x = CheckInventoryBatchRequest{}
+	mi := &file_internal_integrations_inventory_inventorypb_inventory_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckInventoryBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckInventoryBatchRequest) ProtoMessage() {}
+
+func (x *CheckInventoryBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_integrations_inventory_inventorypb_inventory_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckInventoryBatchRequest.ProtoReflect.Descriptor instead.
+func (*CheckInventoryBatchRequest) Descriptor() ([]byte, []int) {
+	return file_internal_integrations_inventory_inventorypb_inventory_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CheckInventoryBatchRequest) GetItems() []*CheckInventoryRequest {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type CheckInventoryBatchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Available     bool                   `protobuf:"varint,2,opt,name=available,proto3" json:"available,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckInventoryBatchResult) Reset() {
+	*x = CheckInventoryBatchResult{}
+	mi := &file_internal_integrations_inventory_inventorypb_inventory_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckInventoryBatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckInventoryBatchResult) ProtoMessage() {}
+
+func (x *CheckInventoryBatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_integrations_inventory_inventorypb_inventory_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckInventoryBatchResult.ProtoReflect.Descriptor instead.
+func (*CheckInventoryBatchResult) Descriptor() ([]byte, []int) {
+	return file_internal_integrations_inventory_inventorypb_inventory_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CheckInventoryBatchResult) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *CheckInventoryBatchResult) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+func (x *CheckInventoryBatchResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type CheckInventoryBatchResponse struct {
+	state         protoimpl.MessageState       `protogen:"open.v1"`
+	Results       []*CheckInventoryBatchResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckInventoryBatchResponse) Reset() {
+	*x = CheckInventoryBatchResponse{}
+	mi := &file_internal_integrations_inventory_inventorypb_inventory_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckInventoryBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckInventoryBatchResponse) ProtoMessage() {}
+
+func (x *CheckInventoryBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_integrations_inventory_inventorypb_inventory_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckInventoryBatchResponse.ProtoReflect.Descriptor instead.
+func (*CheckInventoryBatchResponse) Descriptor() ([]byte, []int) {
+	return file_internal_integrations_inventory_inventorypb_inventory_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CheckInventoryBatchResponse) GetResults() []*CheckInventoryBatchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+var File_internal_integrations_inventory_inventorypb_inventory_proto protoreflect.FileDescriptor
+
+const file_internal_integrations_inventory_inventorypb_inventory_proto_rawDesc = "" +
+	"\n" +
+	";internal/integrations/inventory/inventorypb/inventory.proto\x12\finventory.v1\"R\n" +
+	"\x15CheckInventoryRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"P\n" +
+	"\x16CheckInventoryResponse\x12\x1c\n" +
+	"\tavailable\x18\x01 \x01(\bR\tavailable\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"W\n" +
+	"\x1aCheckInventoryBatchRequest\x129\n" +
+	"\x05items\x18\x01 \x03(\v2#.inventory.v1.CheckInventoryRequestR\x05items\"r\n" +
+	"\x19CheckInventoryBatchResult\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1c\n" +
+	"\tavailable\x18\x02 \x01(\bR\tavailable\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"`\n" +
+	"\x1bCheckInventoryBatchResponse\x12A\n" +
+	"\aresults\x18\x01 \x03(\v2'.inventory.v1.CheckInventoryBatchResultR\aresults2\xdb\x01\n" +
+	"\x10InventoryService\x12[\n" +
+	"\x0eCheckInventory\x12#.inventory.v1.CheckInventoryRequest\x1a$.inventory.v1.CheckInventoryResponse\x12j\n" +
+	"\x13CheckInventoryBatch\x12(.inventory.v1.CheckInventoryBatchRequest\x1a).inventory.v1.CheckInventoryBatchResponseB^Z\\github.com/pulinau/demo-temporal-order-processor/internal/integrations/inventory/inventorypbb\x06proto3"
+
+var (
+	file_internal_integrations_inventory_inventorypb_inventory_proto_rawDescOnce sync.Once
+	file_internal_integrations_inventory_inventorypb_inventory_proto_rawDescData []byte
+)
+
+func file_internal_integrations_inventory_inventorypb_inventory_proto_rawDescGZIP() []byte {
+	file_internal_integrations_inventory_inventorypb_inventory_proto_rawDescOnce.Do(func() {
+		file_internal_integrations_inventory_inventorypb_inventory_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_integrations_inventory_inventorypb_inventory_proto_rawDesc), len(file_internal_integrations_inventory_inventorypb_inventory_proto_rawDesc)))
+	})
+	return file_internal_integrations_inventory_inventorypb_inventory_proto_rawDescData
+}
+
+var file_internal_integrations_inventory_inventorypb_inventory_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_internal_integrations_inventory_inventorypb_inventory_proto_goTypes = []any{
+	(*CheckInventoryRequest)(nil),       // 0: inventory.v1.CheckInventoryRequest
+	(*CheckInventoryResponse)(nil),      // 1: inventory.v1.CheckInventoryResponse
+	(*CheckInventoryBatchRequest)(nil),  // 2: inventory.v1.CheckInventoryBatchRequest
+	(*CheckInventoryBatchResult)(nil),   // 3: inventory.v1.CheckInventoryBatchResult
+	(*CheckInventoryBatchResponse)(nil), // 4: inventory.v1.CheckInventoryBatchResponse
+}
+var file_internal_integrations_inventory_inventorypb_inventory_proto_depIdxs = []int32{
+	0, // 0: inventory.v1.CheckInventoryBatchRequest.items:type_name -> inventory.v1.CheckInventoryRequest
+	3, // 1: inventory.v1.CheckInventoryBatchResponse.results:type_name -> inventory.v1.CheckInventoryBatchResult
+	0, // 2: inventory.v1.InventoryService.CheckInventory:input_type -> inventory.v1.CheckInventoryRequest
+	2, // 3: inventory.v1.InventoryService.CheckInventoryBatch:input_type -> inventory.v1.CheckInventoryBatchRequest
+	1, // 4: inventory.v1.InventoryService.CheckInventory:output_type -> inventory.v1.CheckInventoryResponse
+	4, // 5: inventory.v1.InventoryService.CheckInventoryBatch:output_type -> inventory.v1.CheckInventoryBatchResponse
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_internal_integrations_inventory_inventorypb_inventory_proto_init() }
+func file_internal_integrations_inventory_inventorypb_inventory_proto_init() {
+	if File_internal_integrations_inventory_inventorypb_inventory_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_integrations_inventory_inventorypb_inventory_proto_rawDesc), len(file_internal_integrations_inventory_inventorypb_inventory_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_internal_integrations_inventory_inventorypb_inventory_proto_goTypes,
+		DependencyIndexes: file_internal_integrations_inventory_inventorypb_inventory_proto_depIdxs,
+		MessageInfos:      file_internal_integrations_inventory_inventorypb_inventory_proto_msgTypes,
+	}.Build()
+	File_internal_integrations_inventory_inventorypb_inventory_proto = out.File
+	file_internal_integrations_inventory_inventorypb_inventory_proto_goTypes = nil
+	file_internal_integrations_inventory_inventorypb_inventory_proto_depIdxs = nil
+}
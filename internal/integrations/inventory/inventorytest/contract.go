@@ -0,0 +1,138 @@
+package inventorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/inventory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Scenario is a behaviour of the inventory service, as reproduced by the
+// WireMock mappings and scenarios under wiremock/.
+type Scenario string
+
+const (
+	// ScenarioSuccess reports every product in stock
+	// (inventory-success.json).
+	ScenarioSuccess Scenario = "success"
+	// ScenarioIntermittent fails every other request with 503 Service
+	// Unavailable (inventory-intermittent-failure.json).
+	ScenarioIntermittent Scenario = "intermittent"
+	// ScenarioNonRetryable rejects every request with 400 Bad Request
+	// (inventory-non-retryable-failure.json).
+	ScenarioNonRetryable Scenario = "non-retryable"
+	// ScenarioRateLimited rejects every request with 429 Too Many Requests
+	// and asks for a retry after five seconds (inventory-rate-limited.json).
+	ScenarioRateLimited Scenario = "rate-limited"
+	// ScenarioUnauthorized rejects requests without credentials with 401
+	// Unauthorized (inventory-auth-required.json).
+	ScenarioUnauthorized Scenario = "unauthorized"
+	// ScenarioSlow responds after SlowResponseDelay.
+	ScenarioSlow Scenario = "slow"
+	// ScenarioMalformed responds 200 OK with a body that is not valid JSON.
+	ScenarioMalformed Scenario = "malformed"
+)
+
+// SlowResponseDelay is how long the service takes to respond in
+// ScenarioSlow. The contract gives up well before it.
+const SlowResponseDelay = 500 * time.Millisecond
+
+// rateLimitRetryAfter is the delay the service asks for in
+// ScenarioRateLimited.
+const rateLimitRetryAfter = 5 * time.Second
+
+// NewChecker returns the implementation under test, sending requests to a
+// fake inventory service that behaves as in scenario. It returns nil if the
+// scenario cannot happen with the implementation's transport, and the case is
+// skipped.
+type NewChecker func(t *testing.T, scenario Scenario) inventory.AvailabilityChecker
+
+// RunContract checks that an implementation of inventory.AvailabilityChecker
+// returns the results and errors the activities rely on: sentinel errors
+// that can be matched with errors.Is, and inventory.Retryable telling apart
+// failures worth retrying.
+func RunContract(t *testing.T, newChecker NewChecker) {
+	productID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	run := func(scenario Scenario, test func(t *testing.T, checker inventory.AvailabilityChecker)) {
+		t.Run(string(scenario), func(t *testing.T) {
+			checker := newChecker(t, scenario)
+			if checker == nil {
+				t.Skipf("scenario %s does not apply to this transport", scenario)
+			}
+			test(t, checker)
+		})
+	}
+
+	run(ScenarioSuccess, func(t *testing.T, checker inventory.AvailabilityChecker) {
+		available, err := checker.CheckInventory(context.Background(), productID, 10)
+
+		require.NoError(t, err)
+		assert.True(t, available)
+	})
+
+	run(ScenarioIntermittent, func(t *testing.T, checker inventory.AvailabilityChecker) {
+		_, err := checker.CheckInventory(context.Background(), productID, 5)
+
+		require.ErrorIs(t, err, inventory.ErrUnavailable)
+		assert.True(t, inventory.Retryable(err), "an unavailable service should be retried")
+
+		available, err := checker.CheckInventory(context.Background(), productID, 5)
+
+		require.NoError(t, err, "the retry should succeed")
+		assert.True(t, available)
+	})
+
+	run(ScenarioNonRetryable, func(t *testing.T, checker inventory.AvailabilityChecker) {
+		available, err := checker.CheckInventory(context.Background(), productID, 1)
+
+		require.ErrorIs(t, err, inventory.ErrInvalidProduct)
+		assert.False(t, inventory.Retryable(err), "an invalid product should not be retried")
+		assert.False(t, available)
+	})
+
+	run(ScenarioRateLimited, func(t *testing.T, checker inventory.AvailabilityChecker) {
+		_, err := checker.CheckInventory(context.Background(), productID, 1)
+
+		require.ErrorIs(t, err, inventory.ErrRateLimited)
+		assert.True(t, inventory.Retryable(err), "a rate limited request should be retried")
+
+		var statusErr *inventory.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, rateLimitRetryAfter, statusErr.RetryAfter)
+	})
+
+	run(ScenarioUnauthorized, func(t *testing.T, checker inventory.AvailabilityChecker) {
+		_, err := checker.CheckInventory(context.Background(), productID, 1)
+
+		require.ErrorIs(t, err, inventory.ErrUnauthorized)
+		assert.False(t, inventory.Retryable(err), "rejected credentials should not be retried")
+	})
+
+	run(ScenarioSlow, func(t *testing.T, checker inventory.AvailabilityChecker) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := checker.CheckInventory(ctx, productID, 1)
+
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), SlowResponseDelay, "the call should give up when the context expires")
+		assert.True(t, inventory.Retryable(err), "a timed out request should be retried")
+	})
+
+	run(ScenarioMalformed, func(t *testing.T, checker inventory.AvailabilityChecker) {
+		available, err := checker.CheckInventory(context.Background(), productID, 1)
+
+		require.Error(t, err)
+		assert.False(t, available)
+		var statusErr *inventory.StatusError
+		assert.False(t, errors.As(err, &statusErr), "a malformed response is not an error status")
+		assert.True(t, inventory.Retryable(err))
+	})
+}
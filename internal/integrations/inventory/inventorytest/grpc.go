@@ -0,0 +1,189 @@
+// Package inventorytest provides fakes of the inventory service for tests.
+package inventorytest
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/inventory/inventorypb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const bufSize = 1 << 20
+
+// GRPCServer is an in-process fake of the inventory gRPC service. Products
+// are available up to the quantity given with SetStock; other products have
+// the quantity given with SetDefaultStock, none by default.
+type GRPCServer struct {
+	inventorypb.UnimplementedInventoryServiceServer
+
+	server   *grpc.Server
+	listener *bufconn.Listener
+
+	mu               sync.Mutex
+	stock            map[uuid.UUID]int32
+	defaultStock     int32
+	failures         []error
+	delay            time.Duration
+	batchUnsupported bool
+	calls            int
+}
+
+// NewGRPCServer starts a GRPCServer. Close it when done.
+func NewGRPCServer() *GRPCServer {
+	s := &GRPCServer{
+		server:   grpc.NewServer(),
+		listener: bufconn.Listen(bufSize),
+		stock:    make(map[uuid.UUID]int32),
+	}
+	inventorypb.RegisterInventoryServiceServer(s.server, s)
+	go func() {
+		_ = s.server.Serve(s.listener)
+	}()
+	return s
+}
+
+// Dial connects to the server over an in-memory connection.
+func (s *GRPCServer) Dial() (*grpc.ClientConn, error) {
+	return grpc.NewClient("passthrough:///inventory",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return s.listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+}
+
+// Close stops the server.
+func (s *GRPCServer) Close() {
+	s.server.Stop()
+}
+
+// SetStock sets the quantity of a product that is available.
+func (s *GRPCServer) SetStock(productID uuid.UUID, quantity int32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stock[productID] = quantity
+}
+
+// SetDefaultStock sets the quantity available of products without their own
+// stock.
+func (s *GRPCServer) SetDefaultStock(quantity int32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaultStock = quantity
+}
+
+// FailNext makes the next calls fail with the given errors, one per call, in
+// order. Use status.Error to choose the status code.
+func (s *GRPCServer) FailNext(errs ...error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, errs...)
+}
+
+// SetDelay makes every call wait before it responds.
+func (s *GRPCServer) SetDelay(delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = delay
+}
+
+// SetBatchUnsupported makes CheckInventoryBatch return UNIMPLEMENTED, like a
+// service that predates it.
+func (s *GRPCServer) SetBatchUnsupported(unsupported bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batchUnsupported = unsupported
+}
+
+// Calls returns the number of calls the server has received.
+func (s *GRPCServer) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func (s *GRPCServer) CheckInventory(ctx context.Context, req *inventorypb.CheckInventoryRequest) (*inventorypb.CheckInventoryResponse, error) {
+	if err := s.begin(ctx); err != nil {
+		return nil, err
+	}
+
+	available, err := s.check(req)
+	if err != nil {
+		return nil, err
+	}
+	return &inventorypb.CheckInventoryResponse{Available: available}, nil
+}
+
+func (s *GRPCServer) CheckInventoryBatch(ctx context.Context, req *inventorypb.CheckInventoryBatchRequest) (*inventorypb.CheckInventoryBatchResponse, error) {
+	s.mu.Lock()
+	unsupported := s.batchUnsupported
+	s.mu.Unlock()
+	if unsupported {
+		return s.UnimplementedInventoryServiceServer.CheckInventoryBatch(ctx, req)
+	}
+
+	if err := s.begin(ctx); err != nil {
+		return nil, err
+	}
+
+	resp := &inventorypb.CheckInventoryBatchResponse{}
+	for _, item := range req.GetItems() {
+		available, err := s.check(item)
+		if err != nil {
+			return nil, err
+		}
+		resp.Results = append(resp.Results, &inventorypb.CheckInventoryBatchResult{
+			ProductId: item.GetProductId(),
+			Available: available,
+		})
+	}
+	return resp, nil
+}
+
+// begin counts the call, waits for the configured delay and returns the next
+// queued failure, if any.
+func (s *GRPCServer) begin(ctx context.Context) error {
+	s.mu.Lock()
+	s.calls++
+	delay := s.delay
+	var err error
+	if len(s.failures) > 0 {
+		err, s.failures = s.failures[0], s.failures[1:]
+	}
+	s.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	return err
+}
+
+func (s *GRPCServer) check(req *inventorypb.CheckInventoryRequest) (bool, error) {
+	productID, err := uuid.Parse(req.GetProductId())
+	if err != nil {
+		return false, status.Errorf(codes.InvalidArgument, "invalid product id %q", req.GetProductId())
+	}
+	if req.GetQuantity() <= 0 {
+		return false, status.Error(codes.InvalidArgument, "quantity must be positive")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stock, ok := s.stock[productID]
+	if !ok {
+		stock = s.defaultStock
+	}
+	return stock >= req.GetQuantity(), nil
+}
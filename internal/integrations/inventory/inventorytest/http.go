@@ -0,0 +1,101 @@
+package inventorytest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NewHTTPServer starts an HTTP server that answers POST /inventory/check the
+// way the WireMock mappings under wiremock/ do in scenario. Requests that do
+// not match the mappings get 404, like unmatched WireMock requests. Close the
+// server when done.
+func NewHTTPServer(scenario Scenario) *httptest.Server {
+	var mu sync.Mutex
+	failed := false
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validCheckRequest(r) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch scenario {
+		case ScenarioSuccess:
+			writeJSON(w, http.StatusOK, `{"available": true, "message": "Product is in stock"}`)
+
+		case ScenarioIntermittent:
+			// inventory-intermittent-failure.json and its recovery mapping
+			// alternate between 503 and 200.
+			mu.Lock()
+			failed = !failed
+			fail := failed
+			mu.Unlock()
+			if fail {
+				writeJSON(w, http.StatusServiceUnavailable, `{"available": false, "message": "Service temporarily unavailable"}`)
+				return
+			}
+			writeJSON(w, http.StatusOK, `{"available": true, "message": "Product is in stock (recovered after retry)"}`)
+
+		case ScenarioNonRetryable:
+			writeJSON(w, http.StatusBadRequest, `{"available": false, "message": "Invalid product ID or quantity"}`)
+
+		case ScenarioRateLimited:
+			w.Header().Set("Retry-After", "5")
+			writeJSON(w, http.StatusTooManyRequests, `{"available": false, "message": "Too many requests"}`)
+
+		case ScenarioUnauthorized:
+			if r.Header.Get("Authorization") != "" {
+				writeJSON(w, http.StatusOK, `{"available": true, "message": "Product is in stock"}`)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSON(w, http.StatusUnauthorized, `{"available": false, "message": "Missing credentials"}`)
+
+		case ScenarioSlow:
+			select {
+			case <-time.After(SlowResponseDelay):
+			case <-r.Context().Done():
+				return
+			}
+			writeJSON(w, http.StatusOK, `{"available": true, "message": "Product is in stock"}`)
+
+		case ScenarioMalformed:
+			writeJSON(w, http.StatusOK, `{"available": tru`)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// validCheckRequest matches requests the way the WireMock mappings do: a JSON
+// POST to /inventory/check with a product_id and a quantity.
+func validCheckRequest(r *http.Request) bool {
+	if r.Method != http.MethodPost || r.URL.Path != "/inventory/check" {
+		return false
+	}
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		return false
+	}
+
+	var req struct {
+		ProductID *uuid.UUID `json:"product_id"`
+		Quantity  *int32     `json:"quantity"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return false
+	}
+	return req.ProductID != nil && req.Quantity != nil
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write([]byte(body))
+}
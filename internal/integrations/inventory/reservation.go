@@ -0,0 +1,151 @@
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReservationItem struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Quantity  int32     `json:"quantity"`
+}
+
+type ReserveInventoryRequest struct {
+	OrderID    uuid.UUID         `json:"order_id"`
+	Items      []ReservationItem `json:"items"`
+	TTLSeconds int64             `json:"ttl_seconds"`
+}
+
+type RestockInventoryRequest struct {
+	OrderID uuid.UUID         `json:"order_id"`
+	Items   []ReservationItem `json:"items"`
+}
+
+type ReservationResponse struct {
+	OrderID   uuid.UUID `json:"order_id"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// ReserveInventory holds the requested quantities for an order until the
+// reservation is committed, released or its TTL expires. It returns false if
+// the inventory service cannot hold every item.
+func (c *Client) ReserveInventory(ctx context.Context, orderID uuid.UUID, items map[uuid.UUID]int32, ttl time.Duration) (bool, error) {
+	req := ReserveInventoryRequest{
+		OrderID:    orderID,
+		Items:      reservationItems(items),
+		TTLSeconds: int64(ttl / time.Second),
+	}
+
+	resp, err := c.post(ctx, "/inventory/reservations", req)
+	if err != nil {
+		return false, err
+	}
+
+	switch resp.statusCode {
+	case http.StatusOK, http.StatusCreated:
+		return true, nil
+
+	case http.StatusConflict:
+		// Not enough stock to hold every item.
+		return false, nil
+
+	default:
+		return false, statusError(resp, reservationMessage(resp.body), ErrInvalidRequest)
+	}
+}
+
+// CommitReservation converts the order's reservation into a permanent stock
+// deduction.
+func (c *Client) CommitReservation(ctx context.Context, orderID uuid.UUID) error {
+	resp, err := c.post(ctx, "/inventory/reservations/"+orderID.String()+"/commit", struct{}{})
+	if err != nil {
+		return err
+	}
+
+	switch resp.statusCode {
+	case http.StatusOK:
+		return nil
+
+	case http.StatusNotFound, http.StatusConflict:
+		// The reservation has expired or was already released.
+		return &StatusError{StatusCode: resp.statusCode, Message: reservationMessage(resp.body), Err: ErrReservationNotFound}
+
+	default:
+		return statusError(resp, reservationMessage(resp.body), ErrInvalidRequest)
+	}
+}
+
+// ReleaseReservation returns the order's reserved stock to inventory. Releasing
+// a reservation that does not exist, or that has already been committed, is
+// not an error: there is nothing left to release, so it is safe to retry.
+func (c *Client) ReleaseReservation(ctx context.Context, orderID uuid.UUID) error {
+	resp, err := c.post(ctx, "/inventory/reservations/"+orderID.String()+"/release", struct{}{})
+	if err != nil {
+		return err
+	}
+
+	switch resp.statusCode {
+	case http.StatusOK, http.StatusNotFound, http.StatusConflict:
+		return nil
+
+	default:
+		return statusError(resp, reservationMessage(resp.body), ErrInvalidRequest)
+	}
+}
+
+// RestockInventory returns committed stock for an order to inventory, e.g.
+// when a picked order is cancelled or items are returned. Restocking adds to
+// the stock level, so the request is sent with idempotencyKey in the
+// Idempotency-Key header for the inventory service to apply it only once
+// however often it is retried.
+func (c *Client) RestockInventory(ctx context.Context, orderID uuid.UUID, items map[uuid.UUID]int32, idempotencyKey string) error {
+	req := RestockInventoryRequest{
+		OrderID: orderID,
+		Items:   reservationItems(items),
+	}
+
+	header := http.Header{}
+	header.Set("Idempotency-Key", idempotencyKey)
+	resp, err := c.postWithHeader(ctx, "/inventory/restock", header, req)
+	if err != nil {
+		return err
+	}
+
+	switch resp.statusCode {
+	case http.StatusOK, http.StatusCreated:
+		return nil
+
+	default:
+		return statusError(resp, reservationMessage(resp.body), ErrInvalidRequest)
+	}
+}
+
+// reservationItems converts quantities keyed by product into request items,
+// sorted so the request body is stable across retries.
+func reservationItems(items map[uuid.UUID]int32) []ReservationItem {
+	result := make([]ReservationItem, 0, len(items))
+	for productID, quantity := range items {
+		result = append(result, ReservationItem{ProductID: productID, Quantity: quantity})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return bytes.Compare(result[i].ProductID[:], result[j].ProductID[:]) < 0
+	})
+	return result
+}
+
+// reservationMessage returns the message of an error response, if any.
+func reservationMessage(body []byte) string {
+	var resp ReservationResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return ""
+	}
+	return resp.Message
+}
@@ -0,0 +1,79 @@
+package inventory_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/inventory"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestReservations(t *testing.T) {
+	suite.Run(t, new(ReservationTestSuite))
+}
+
+type ReservationTestSuite struct {
+	suite.Suite
+}
+
+func (s *ReservationTestSuite) TestReleaseReservation() {
+	orderID := uuid.New()
+
+	tests := []struct {
+		name     string
+		status   int
+		sentinel error
+	}{
+		{name: "Released", status: http.StatusOK},
+		{name: "Expired or already released", status: http.StatusNotFound},
+		{name: "Already committed", status: http.StatusConflict},
+		{name: "Service unavailable", status: http.StatusServiceUnavailable, sentinel: inventory.ErrUnavailable},
+		{name: "Invalid request", status: http.StatusBadRequest, sentinel: inventory.ErrInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				s.Equal("/inventory/reservations/"+orderID.String()+"/release", r.URL.Path)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			// Invoke
+			err := inventory.NewClient(server.URL).ReleaseReservation(context.Background(), orderID)
+
+			// Assert
+			if tt.sentinel == nil {
+				s.Require().NoError(err)
+				return
+			}
+			s.Require().ErrorIs(err, tt.sentinel)
+		})
+	}
+}
+
+func (s *ReservationTestSuite) TestRestockInventory() {
+	// Setup
+	orderID, productID := uuid.New(), uuid.New()
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Equal("/inventory/restock", r.URL.Path)
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client := inventory.NewClient(server.URL)
+
+	// Invoke
+	err := client.RestockInventory(context.Background(), orderID, map[uuid.UUID]int32{productID: 1}, "order-1-restock-sydney")
+	s.Require().NoError(err)
+	err = client.RestockInventory(context.Background(), orderID, map[uuid.UUID]int32{productID: 1}, "order-1-restock-sydney")
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal([]string{"order-1-restock-sydney", "order-1-restock-sydney"}, keys, "a retried restock should be sent with the same key")
+}
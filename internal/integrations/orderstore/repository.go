@@ -0,0 +1,113 @@
+package orderstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pulinau/demo-temporal-order-processor/internal/temporal"
+)
+
+type Config struct {
+	Dir string `yaml:"dir" validate:"required"`
+}
+
+// FileRepository stores each processed order as a JSON file in a directory.
+// It is intended for running the worker locally.
+type FileRepository struct {
+	dir string
+	mu  sync.Mutex
+}
+
+type storedOrder struct {
+	temporal.OrderRecord
+	Voided   bool       `json:"voided"`
+	VoidedAt *time.Time `json:"voided_at,omitempty"`
+}
+
+func NewFileRepository(dir string) (*FileRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create order store directory: %w", err)
+	}
+
+	return &FileRepository{
+		dir: dir,
+	}, nil
+}
+
+// SaveOrder writes the record, replacing any existing record for the order.
+func (r *FileRepository) SaveOrder(ctx context.Context, record temporal.OrderRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.write(storedOrder{OrderRecord: record})
+}
+
+// VoidOrder marks the stored order as voided. It does nothing if the order was
+// never saved.
+func (r *FileRepository) VoidOrder(ctx context.Context, orderID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := os.ReadFile(r.path(orderID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read order: %w", err)
+	}
+
+	var stored storedOrder
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to unmarshal order: %w", err)
+	}
+	if stored.Voided {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	stored.Voided = true
+	stored.VoidedAt = &now
+
+	return r.write(stored)
+}
+
+// write replaces the order's file atomically so a crash never leaves a
+// partially written record.
+func (r *FileRepository) write(stored storedOrder) error {
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal order: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(r.dir, "order-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write order: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write order: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), r.path(stored.Order.ID)); err != nil {
+		return fmt.Errorf("failed to save order: %w", err)
+	}
+
+	return nil
+}
+
+func (r *FileRepository) path(orderID uuid.UUID) string {
+	return filepath.Join(r.dir, orderID.String()+".json")
+}
@@ -0,0 +1,185 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type Config struct {
+	BaseURL string `yaml:"baseUrl" validate:"required,http_url"`
+}
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+type AuthorizePaymentRequest struct {
+	OrderID uuid.UUID       `json:"order_id"`
+	Amount  decimal.Decimal `json:"amount"`
+}
+
+type PaymentAmountRequest struct {
+	Amount decimal.Decimal `json:"amount"`
+}
+
+type PaymentResponse struct {
+	AuthorizationID string `json:"authorization_id,omitempty"`
+	Status          string `json:"status"`
+	Message         string `json:"message,omitempty"`
+}
+
+// Authorization is the gateway's response to a payment authorization.
+type Authorization struct {
+	ID            string          `json:"id,omitempty"`
+	Approved      bool            `json:"approved"`
+	Amount        decimal.Decimal `json:"amount"`
+	DeclineReason string          `json:"decline_reason,omitempty"`
+}
+
+// AuthorizePayment places a hold for the amount on the customer's funds. A
+// declined payment is returned as an authorization that is not approved.
+func (c *Client) AuthorizePayment(ctx context.Context, orderID uuid.UUID, amount decimal.Decimal) (Authorization, error) {
+	req := AuthorizePaymentRequest{
+		OrderID: orderID,
+		Amount:  amount,
+	}
+
+	statusCode, respBody, err := c.post(ctx, "/payments/authorizations", req)
+	if err != nil {
+		return Authorization{}, err
+	}
+
+	switch statusCode {
+	case http.StatusOK, http.StatusCreated:
+		var resp PaymentResponse
+		if err := json.Unmarshal(respBody, &resp); err != nil {
+			return Authorization{}, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		return Authorization{
+			ID:       resp.AuthorizationID,
+			Approved: true,
+			Amount:   amount,
+		}, nil
+
+	case http.StatusPaymentRequired:
+		// The card issuer declined the payment.
+		return Authorization{
+			Approved:      false,
+			Amount:        amount,
+			DeclineReason: paymentMessage(respBody, "payment declined"),
+		}, nil
+
+	default:
+		return Authorization{}, statusError(statusCode, respBody)
+	}
+}
+
+// CapturePayment takes the amount from the order's authorization.
+func (c *Client) CapturePayment(ctx context.Context, orderID uuid.UUID, amount decimal.Decimal) error {
+	statusCode, respBody, err := c.post(ctx, "/payments/"+orderID.String()+"/capture", PaymentAmountRequest{Amount: amount})
+	if err != nil {
+		return err
+	}
+
+	switch statusCode {
+	case http.StatusOK:
+		return nil
+
+	default:
+		// Includes an authorization that has expired (404) or was already
+		// voided (409).
+		return statusError(statusCode, respBody)
+	}
+}
+
+// VoidPayment releases the order's authorization. Voiding an authorization
+// that does not exist is not an error, so it is safe to retry.
+func (c *Client) VoidPayment(ctx context.Context, orderID uuid.UUID) error {
+	statusCode, respBody, err := c.post(ctx, "/payments/"+orderID.String()+"/void", struct{}{})
+	if err != nil {
+		return err
+	}
+
+	switch statusCode {
+	case http.StatusOK, http.StatusNotFound:
+		return nil
+
+	default:
+		// Includes a payment that has already been captured (409) and must
+		// be refunded instead.
+		return statusError(statusCode, respBody)
+	}
+}
+
+// RefundPayment returns the amount of a captured payment to the customer.
+func (c *Client) RefundPayment(ctx context.Context, orderID uuid.UUID, amount decimal.Decimal) error {
+	statusCode, respBody, err := c.post(ctx, "/payments/"+orderID.String()+"/refund", PaymentAmountRequest{Amount: amount})
+	if err != nil {
+		return err
+	}
+
+	switch statusCode {
+	case http.StatusOK, http.StatusCreated:
+		return nil
+
+	default:
+		return statusError(statusCode, respBody)
+	}
+}
+
+// post sends payload as JSON to the given path and returns the response status
+// code and body.
+func (c *Client) post(ctx context.Context, path string, payload any) (int, []byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewBuffer(body))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		// Includes the client timeout, which is retried like any other
+		// transport error.
+		return 0, nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return resp.StatusCode, respBody, nil
+}
+
+func paymentMessage(body []byte, fallback string) string {
+	var resp PaymentResponse
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Message == "" {
+		return fallback
+	}
+	return resp.Message
+}
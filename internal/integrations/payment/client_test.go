@@ -0,0 +1,211 @@
+package payment_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/payment"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+var orderID = uuid.MustParse("8c727b70-cfcb-4674-8bcd-78e66e32f723")
+
+func TestClient(t *testing.T) {
+	suite.Run(t, new(ClientTestSuite))
+}
+
+type ClientTestSuite struct {
+	suite.Suite
+}
+
+// respond starts a server that answers every request to path with status and
+// body, failing the test on any other path.
+func (s *ClientTestSuite) respond(path string, status int, body string) *payment.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Equal(http.MethodPost, r.Method)
+		s.Equal(path, r.URL.Path)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	s.T().Cleanup(server.Close)
+	return payment.NewClient(server.URL)
+}
+
+func (s *ClientTestSuite) TestAuthorizePayment() {
+	amount := decimal.RequireFromString("26.99")
+
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		want     payment.Authorization
+		sentinel error
+	}{
+		{
+			name:   "Approved",
+			status: http.StatusCreated,
+			body:   `{"authorization_id": "auth-1", "status": "authorized"}`,
+			want:   payment.Authorization{ID: "auth-1", Approved: true, Amount: amount},
+		},
+		{
+			name:   "Declined",
+			status: http.StatusPaymentRequired,
+			body:   `{"status": "declined", "message": "insufficient funds"}`,
+			want:   payment.Authorization{Approved: false, Amount: amount, DeclineReason: "insufficient funds"},
+		},
+		{
+			name:     "Invalid request",
+			status:   http.StatusBadRequest,
+			body:     `{"message": "amount must be positive"}`,
+			sentinel: payment.ErrInvalidRequest,
+		},
+		{
+			name:     "Gateway unavailable",
+			status:   http.StatusServiceUnavailable,
+			sentinel: payment.ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				s.Equal("/payments/authorizations", r.URL.Path)
+				var req payment.AuthorizePaymentRequest
+				s.NoError(json.NewDecoder(r.Body).Decode(&req))
+				s.Equal(orderID, req.OrderID)
+				s.True(amount.Equal(req.Amount))
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			// Invoke
+			got, err := payment.NewClient(server.URL).AuthorizePayment(context.Background(), orderID, amount)
+
+			// Assert
+			if tt.sentinel != nil {
+				s.Require().ErrorIs(err, tt.sentinel)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(tt.want.ID, got.ID)
+			s.Equal(tt.want.Approved, got.Approved)
+			s.Equal(tt.want.DeclineReason, got.DeclineReason)
+			s.True(tt.want.Amount.Equal(got.Amount))
+		})
+	}
+}
+
+func (s *ClientTestSuite) TestCapturePayment() {
+	tests := []struct {
+		name     string
+		status   int
+		sentinel error
+	}{
+		{name: "Captured", status: http.StatusOK},
+		{name: "Declined", status: http.StatusPaymentRequired, sentinel: payment.ErrDeclined},
+		{name: "Authorization expired", status: http.StatusNotFound, sentinel: payment.ErrNotFound},
+		{name: "Authorization voided", status: http.StatusConflict, sentinel: payment.ErrConflict},
+		{name: "Gateway unavailable", status: http.StatusInternalServerError, sentinel: payment.ErrUnavailable},
+		{name: "Rate limited", status: http.StatusTooManyRequests, sentinel: payment.ErrUnavailable},
+		{name: "Unexpected status", status: http.StatusTeapot, sentinel: payment.ErrUnexpectedResponse},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			client := s.respond("/payments/"+orderID.String()+"/capture", tt.status, "")
+
+			err := client.CapturePayment(context.Background(), orderID, decimal.RequireFromString("26.99"))
+
+			if tt.sentinel == nil {
+				s.Require().NoError(err)
+				return
+			}
+			s.Require().ErrorIs(err, tt.sentinel)
+			var statusErr *payment.StatusError
+			s.Require().ErrorAs(err, &statusErr)
+			s.Equal(tt.status, statusErr.StatusCode)
+		})
+	}
+}
+
+func (s *ClientTestSuite) TestVoidPayment() {
+	tests := []struct {
+		name     string
+		status   int
+		sentinel error
+	}{
+		{name: "Voided", status: http.StatusOK},
+		{name: "Never authorized", status: http.StatusNotFound},
+		{name: "Already captured", status: http.StatusConflict, sentinel: payment.ErrConflict},
+		{name: "Gateway unavailable", status: http.StatusGatewayTimeout, sentinel: payment.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			client := s.respond("/payments/"+orderID.String()+"/void", tt.status, "")
+
+			err := client.VoidPayment(context.Background(), orderID)
+
+			if tt.sentinel == nil {
+				s.Require().NoError(err)
+				return
+			}
+			s.Require().ErrorIs(err, tt.sentinel)
+		})
+	}
+}
+
+func (s *ClientTestSuite) TestRefundPayment() {
+	tests := []struct {
+		name     string
+		status   int
+		sentinel error
+	}{
+		{name: "Refunded", status: http.StatusCreated},
+		{name: "Invalid amount", status: http.StatusBadRequest, sentinel: payment.ErrInvalidRequest},
+		{name: "Payment not found", status: http.StatusNotFound, sentinel: payment.ErrNotFound},
+		{name: "Already refunded", status: http.StatusConflict, sentinel: payment.ErrConflict},
+		{name: "Gateway unavailable", status: http.StatusServiceUnavailable, sentinel: payment.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			client := s.respond("/payments/"+orderID.String()+"/refund", tt.status, "")
+
+			err := client.RefundPayment(context.Background(), orderID, decimal.RequireFromString("10.00"))
+
+			if tt.sentinel == nil {
+				s.Require().NoError(err)
+				return
+			}
+			s.Require().ErrorIs(err, tt.sentinel)
+		})
+	}
+}
+
+func (s *ClientTestSuite) TestStatusError_Message() {
+	client := s.respond("/payments/"+orderID.String()+"/capture", http.StatusConflict, `{"status": "voided", "message": "authorization was voided"}`)
+
+	err := client.CapturePayment(context.Background(), orderID, decimal.RequireFromString("26.99"))
+
+	s.Require().EqualError(err, "payment conflict: authorization was voided (status: 409)")
+}
+
+func (s *ClientTestSuite) TestTransportError() {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	err := payment.NewClient(server.URL).CapturePayment(context.Background(), orderID, decimal.RequireFromString("26.99"))
+
+	s.Require().ErrorContains(err, "failed to make request")
+	var statusErr *payment.StatusError
+	s.False(errors.As(err, &statusErr), "transport errors should not be status errors")
+}
@@ -0,0 +1,72 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors returned by the Client, wrapped in a *StatusError. Match them with
+// errors.Is. Transport failures such as timeouts are returned as they are.
+var (
+	// ErrInvalidRequest means the gateway rejected the request. Retrying the
+	// same request will not succeed.
+	ErrInvalidRequest = errors.New("invalid payment request")
+	// ErrDeclined means the card issuer declined a capture or refund.
+	ErrDeclined = errors.New("payment declined")
+	// ErrNotFound means the order has no authorization or payment, e.g.
+	// because the authorization expired.
+	ErrNotFound = errors.New("payment not found")
+	// ErrConflict means the payment is not in a state that allows the
+	// request, e.g. capturing a voided authorization or voiding a captured
+	// payment.
+	ErrConflict = errors.New("payment conflict")
+	// ErrUnavailable means the gateway failed to handle the request and it
+	// may succeed if retried.
+	ErrUnavailable = errors.New("payment gateway unavailable")
+	// ErrUnexpectedResponse means the gateway responded with a status the
+	// client does not handle.
+	ErrUnexpectedResponse = errors.New("unexpected payment gateway response")
+)
+
+// StatusError is returned when the payment gateway responds with an error
+// status code.
+type StatusError struct {
+	StatusCode int
+	// Message is the explanation given by the gateway, if any.
+	Message string
+	// Err is the sentinel error the status code maps to.
+	Err error
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s (status: %d)", e.Err, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s (status: %d)", e.Err, e.Message, e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// statusError builds the error for a response status code that the calling
+// method does not treat as success.
+func statusError(statusCode int, body []byte) error {
+	err := &StatusError{StatusCode: statusCode, Message: paymentMessage(body, "")}
+	switch statusCode {
+	case http.StatusBadRequest:
+		err.Err = ErrInvalidRequest
+	case http.StatusPaymentRequired:
+		err.Err = ErrDeclined
+	case http.StatusNotFound:
+		err.Err = ErrNotFound
+	case http.StatusConflict:
+		err.Err = ErrConflict
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		err.Err = ErrUnavailable
+	default:
+		err.Err = ErrUnexpectedResponse
+	}
+	return err
+}
@@ -0,0 +1,271 @@
+// Package pricing prices orders from a catalogue and promotions read from a
+// rules file.
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/pulinau/demo-temporal-order-processor/internal/temporal"
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	// RulesFile is the path of the YAML pricing rules. Orders are charged the
+	// prices they were placed with if it is empty.
+	RulesFile string `yaml:"rulesFile"`
+}
+
+// PromotionType is how a promotion discounts an order.
+type PromotionType string
+
+const (
+	// PromotionPercentage takes Percent off the matching line items, or off
+	// the order subtotal if the promotion lists no products.
+	PromotionPercentage PromotionType = "percentage"
+	// PromotionFixed takes Amount off each matching item, or off the order
+	// subtotal if the promotion lists no products.
+	PromotionFixed PromotionType = "fixed"
+	// PromotionBundle sells every BundleQuantity items of a listed product
+	// for BundlePrice.
+	PromotionBundle PromotionType = "bundle"
+	// PromotionFreeShipping waives the shipping fee.
+	PromotionFreeShipping PromotionType = "free-shipping"
+)
+
+// Rules are the prices and promotions applied to orders.
+type Rules struct {
+	// Catalogue prices replace the prices orders were placed with. Products
+	// that are not listed keep the price they were placed with.
+	Catalogue []CatalogueEntry `yaml:"catalogue" validate:"dive"`
+	// Promotions are applied in the order they are listed, each to the
+	// prices left by the ones before it.
+	Promotions []Promotion `yaml:"promotions" validate:"dive"`
+}
+
+// CatalogueEntry is the unit price of a product.
+type CatalogueEntry struct {
+	ProductID uuid.UUID       `yaml:"productId" validate:"required"`
+	Price     decimal.Decimal `yaml:"price"`
+}
+
+// Promotion is a discount applied to every order that qualifies for it.
+type Promotion struct {
+	Name        string        `yaml:"name" validate:"required"`
+	Description string        `yaml:"description"`
+	Type        PromotionType `yaml:"type" validate:"oneof=percentage fixed bundle free-shipping"`
+	// Coupon limits the promotion to orders with this coupon code, compared
+	// case-insensitively.
+	Coupon string `yaml:"coupon"`
+	// Products limits the promotion to line items of these products.
+	Products []uuid.UUID `yaml:"products"`
+	// MinSubtotal is the subtotal, after the promotions before this one, an
+	// order needs to qualify.
+	MinSubtotal    decimal.Decimal `yaml:"minSubtotal"`
+	Percent        decimal.Decimal `yaml:"percent"`
+	Amount         decimal.Decimal `yaml:"amount"`
+	BundleQuantity int32           `yaml:"bundleQuantity"`
+	BundlePrice    decimal.Decimal `yaml:"bundlePrice"`
+}
+
+// RulesEngine is a temporal.PricingEngine that prices orders with Rules read
+// from a file. It is intended for running the worker locally.
+type RulesEngine struct {
+	rules  Rules
+	prices map[uuid.UUID]decimal.Decimal
+}
+
+// NewRulesEngine reads and checks the rules in path.
+func NewRulesEngine(path string) (*RulesEngine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing rules: %w", err)
+	}
+
+	var rules Rules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pricing rules: %w", err)
+	}
+
+	return NewEngine(rules)
+}
+
+// NewEngine returns an engine that prices orders with rules.
+func NewEngine(rules Rules) (*RulesEngine, error) {
+	if err := checkRules(rules); err != nil {
+		return nil, fmt.Errorf("invalid pricing rules: %w", err)
+	}
+
+	prices := make(map[uuid.UUID]decimal.Decimal, len(rules.Catalogue))
+	for _, entry := range rules.Catalogue {
+		prices[entry.ProductID] = entry.Price
+	}
+
+	return &RulesEngine{
+		rules:  rules,
+		prices: prices,
+	}, nil
+}
+
+func checkRules(rules Rules) error {
+	if err := validator.New().Struct(rules); err != nil {
+		return err
+	}
+
+	for _, entry := range rules.Catalogue {
+		if !entry.Price.IsPositive() {
+			return fmt.Errorf("catalogue price of product %s must be positive", entry.ProductID)
+		}
+	}
+
+	for _, p := range rules.Promotions {
+		switch {
+		case p.Type == PromotionPercentage && (!p.Percent.IsPositive() || p.Percent.GreaterThan(decimal.NewFromInt(100))):
+			return fmt.Errorf("promotion %s: percent must be between 0 and 100", p.Name)
+		case p.Type == PromotionFixed && !p.Amount.IsPositive():
+			return fmt.Errorf("promotion %s: amount must be positive", p.Name)
+		case p.Type == PromotionBundle && (len(p.Products) == 0 || p.BundleQuantity < 2 || !p.BundlePrice.IsPositive()):
+			return fmt.Errorf("promotion %s: a bundle needs products, a bundle quantity of at least 2 and a positive bundle price", p.Name)
+		}
+	}
+	return nil
+}
+
+// PriceOrder prices the line items of order from the catalogue and applies
+// every promotion the order qualifies for.
+func (e *RulesEngine) PriceOrder(ctx context.Context, order temporal.Order) (temporal.PriceBreakdown, error) {
+	coupons, err := e.coupons(order.CouponCodes)
+	if err != nil {
+		return temporal.PriceBreakdown{}, err
+	}
+
+	pricing := temporal.PriceBreakdown{Lines: make([]temporal.LinePrice, 0, len(order.LineItems))}
+	for _, item := range order.LineItems {
+		unitPrice, ok := e.prices[item.ProductID]
+		if !ok {
+			unitPrice = item.PricePerItem
+		}
+		pricing.Lines = append(pricing.Lines, temporal.LinePrice{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: unitPrice,
+			Total:     unitPrice.Mul(decimal.NewFromInt32(item.Quantity)).Round(2),
+		})
+	}
+
+	for _, p := range e.rules.Promotions {
+		if p.Coupon != "" && !slices.Contains(coupons, normalizeCoupon(p.Coupon)) {
+			continue
+		}
+		if subtotal(pricing).LessThan(p.MinSubtotal) {
+			continue
+		}
+
+		if applyPromotion(&pricing, p) && p.Coupon != "" {
+			pricing.Coupons = append(pricing.Coupons, p.Coupon)
+		}
+	}
+
+	return pricing, nil
+}
+
+// coupons normalizes the coupon codes of an order and checks that each one is
+// the coupon of a promotion.
+func (e *RulesEngine) coupons(codes []string) ([]string, error) {
+	coupons := make([]string, 0, len(codes))
+	for _, code := range codes {
+		coupon := normalizeCoupon(code)
+		known := slices.ContainsFunc(e.rules.Promotions, func(p Promotion) bool {
+			return p.Coupon != "" && normalizeCoupon(p.Coupon) == coupon
+		})
+		if !known {
+			return nil, fmt.Errorf("%w %q", temporal.ErrUnknownCoupon, code)
+		}
+		coupons = append(coupons, coupon)
+	}
+	return coupons, nil
+}
+
+func normalizeCoupon(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// applyPromotion applies p to pricing and reports whether it changed the
+// price of the order.
+func applyPromotion(pricing *temporal.PriceBreakdown, p Promotion) bool {
+	if p.Type == PromotionFreeShipping {
+		applied := !pricing.FreeShipping
+		pricing.FreeShipping = true
+		return applied
+	}
+
+	if len(p.Products) == 0 {
+		remaining := subtotal(*pricing)
+		amount := p.Amount
+		if p.Type == PromotionPercentage {
+			amount = remaining.Mul(p.Percent).Div(decimal.NewFromInt(100))
+		}
+		amount = decimal.Min(amount.Round(2), remaining)
+		if !amount.IsPositive() {
+			return false
+		}
+		pricing.Discounts = append(pricing.Discounts, discount(p, amount))
+		return true
+	}
+
+	applied := false
+	for i := range pricing.Lines {
+		line := &pricing.Lines[i]
+		if !slices.Contains(p.Products, line.ProductID) {
+			continue
+		}
+
+		var amount decimal.Decimal
+		quantity := decimal.NewFromInt32(line.Quantity)
+		switch p.Type {
+		case PromotionPercentage:
+			amount = line.Total.Mul(p.Percent).Div(decimal.NewFromInt(100))
+		case PromotionFixed:
+			amount = p.Amount.Mul(quantity)
+		case PromotionBundle:
+			bundles := decimal.NewFromInt32(line.Quantity / p.BundleQuantity)
+			bundleListPrice := line.UnitPrice.Mul(decimal.NewFromInt32(p.BundleQuantity))
+			amount = bundleListPrice.Sub(p.BundlePrice).Mul(bundles)
+		}
+		amount = decimal.Min(amount.Round(2), line.Total)
+		if !amount.IsPositive() {
+			continue
+		}
+
+		line.Discounts = append(line.Discounts, discount(p, amount))
+		line.Total = line.Total.Sub(amount)
+		applied = true
+	}
+	return applied
+}
+
+func discount(p Promotion, amount decimal.Decimal) temporal.Discount {
+	return temporal.Discount{
+		Promotion:   p.Name,
+		Description: p.Description,
+		Amount:      amount,
+	}
+}
+
+// subtotal is the price of the order after the discounts applied so far.
+func subtotal(pricing temporal.PriceBreakdown) decimal.Decimal {
+	total := decimal.Zero
+	for _, line := range pricing.Lines {
+		total = total.Add(line.Total)
+	}
+	for _, d := range pricing.Discounts {
+		total = total.Sub(d.Amount)
+	}
+	return total
+}
@@ -0,0 +1,174 @@
+package pricing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/pricing"
+	"github.com/pulinau/demo-temporal-order-processor/internal/temporal"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	shirt = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	hat   = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	socks = uuid.MustParse("00000000-0000-0000-0000-000000000003")
+)
+
+func TestRulesEngine(t *testing.T) {
+	suite.Run(t, new(RulesEngineTestSuite))
+}
+
+type RulesEngineTestSuite struct {
+	suite.Suite
+}
+
+// order returns an order for quantity of each product, placed at $10 each.
+func order(coupons []string, quantities map[uuid.UUID]int32) temporal.Order {
+	o := temporal.Order{ID: uuid.New(), Currency: "AUD", CouponCodes: coupons}
+	for _, productID := range []uuid.UUID{shirt, hat, socks} {
+		if quantities[productID] > 0 {
+			o.LineItems = append(o.LineItems, temporal.LineItem{
+				ProductID:    productID,
+				Quantity:     quantities[productID],
+				PricePerItem: decimal.RequireFromString("10.00"),
+			})
+		}
+	}
+	return o
+}
+
+func (s *RulesEngineTestSuite) TestPriceOrder() {
+	rules := pricing.Rules{
+		Catalogue: []pricing.CatalogueEntry{
+			{ProductID: shirt, Price: decimal.RequireFromString("30.00")},
+			{ProductID: hat, Price: decimal.RequireFromString("15.00")},
+		},
+		Promotions: []pricing.Promotion{
+			{Name: "3-shirts-for-75", Type: pricing.PromotionBundle, Products: []uuid.UUID{shirt}, BundleQuantity: 3, BundlePrice: decimal.RequireFromString("75.00")},
+			{Name: "hats-20-off", Type: pricing.PromotionPercentage, Products: []uuid.UUID{hat}, Percent: decimal.RequireFromString("20")},
+			{Name: "big-spender", Type: pricing.PromotionPercentage, MinSubtotal: decimal.RequireFromString("100.00"), Percent: decimal.RequireFromString("10")},
+			{Name: "WELCOME5", Type: pricing.PromotionFixed, Coupon: "WELCOME5", Amount: decimal.RequireFromString("5.00")},
+			{Name: "FREESHIP", Type: pricing.PromotionFreeShipping, Coupon: "FreeShip"},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		order         temporal.Order
+		wantLines     []string
+		wantDiscounts []string
+		wantFree      bool
+		wantCoupons   []string
+	}{
+		{
+			name:      "Catalogue prices",
+			order:     order(nil, map[uuid.UUID]int32{shirt: 1, socks: 2}),
+			wantLines: []string{"30", "20"},
+		},
+		{
+			name:      "Bundle and line percentage",
+			order:     order(nil, map[uuid.UUID]int32{shirt: 4, hat: 1}),
+			wantLines: []string{"105", "12"},
+			// 10% of 117 once the line discounts leave it over 100.
+			wantDiscounts: []string{"11.7"},
+		},
+		{
+			name:          "Coupons",
+			order:         order([]string{" welcome5", "FREESHIP"}, map[uuid.UUID]int32{socks: 1}),
+			wantLines:     []string{"10"},
+			wantDiscounts: []string{"5"},
+			wantFree:      true,
+			wantCoupons:   []string{"WELCOME5", "FreeShip"},
+		},
+		{
+			name:          "Coupon that takes nothing off",
+			order:         order([]string{"WELCOME5"}, map[uuid.UUID]int32{}),
+			wantDiscounts: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			engine, err := pricing.NewEngine(rules)
+			s.Require().NoError(err)
+
+			// Invoke
+			got, err := engine.PriceOrder(context.Background(), tt.order)
+
+			// Assert
+			s.Require().NoError(err)
+			s.Require().Len(got.Lines, len(tt.wantLines))
+			for i, want := range tt.wantLines {
+				s.Equal(want, got.Lines[i].Total.String(), "line %d", i)
+			}
+			s.Require().Len(got.Discounts, len(tt.wantDiscounts))
+			for i, want := range tt.wantDiscounts {
+				s.Equal(want, got.Discounts[i].Amount.String(), "discount %d", i)
+			}
+			s.Equal(tt.wantFree, got.FreeShipping)
+			s.Equal(tt.wantCoupons, got.Coupons)
+		})
+	}
+}
+
+func (s *RulesEngineTestSuite) TestPriceOrder_UnknownCoupon() {
+	// Setup
+	engine, err := pricing.NewEngine(pricing.Rules{})
+	s.Require().NoError(err)
+
+	// Invoke
+	_, err = engine.PriceOrder(context.Background(), order([]string{"BOGUS"}, map[uuid.UUID]int32{shirt: 1}))
+
+	// Assert
+	s.Require().ErrorIs(err, temporal.ErrUnknownCoupon)
+	s.ErrorContains(err, `"BOGUS"`)
+}
+
+func (s *RulesEngineTestSuite) TestNewEngine_InvalidRules() {
+	tests := []struct {
+		name      string
+		promotion pricing.Promotion
+		err       string
+	}{
+		{
+			name:      "Unknown type",
+			promotion: pricing.Promotion{Name: "half-off", Type: "half"},
+			err:       "oneof",
+		},
+		{
+			name:      "Percentage over 100",
+			promotion: pricing.Promotion{Name: "too-good", Type: pricing.PromotionPercentage, Percent: decimal.RequireFromString("150")},
+			err:       "percent must be between 0 and 100",
+		},
+		{
+			name:      "Bundle without products",
+			promotion: pricing.Promotion{Name: "bundle", Type: pricing.PromotionBundle, BundleQuantity: 2, BundlePrice: decimal.RequireFromString("10")},
+			err:       "a bundle needs products",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			_, err := pricing.NewEngine(pricing.Rules{Promotions: []pricing.Promotion{tt.promotion}})
+
+			s.Require().ErrorContains(err, tt.err)
+		})
+	}
+}
+
+func (s *RulesEngineTestSuite) TestNewRulesEngine_LocalRules() {
+	// Invoke
+	engine, err := pricing.NewRulesEngine("../../../config/worker/local/pricing.yaml")
+
+	// Assert
+	s.Require().NoError(err, "the rules used by the local worker should load")
+	got, err := engine.PriceOrder(context.Background(), order([]string{"welcome5"}, map[uuid.UUID]int32{shirt: 3}))
+	s.Require().NoError(err)
+	s.Require().Len(got.Lines, 1)
+	s.Equal("75", got.Lines[0].Total.String())
+	s.Equal([]string{"WELCOME5"}, got.Coupons)
+}
@@ -0,0 +1,82 @@
+package tax
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Client is a temporal.TaxCalculator that calculates tax with an external tax
+// service.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+type ErrorResponse struct {
+	Message string `json:"message"`
+}
+
+// CalculateTax sends req to the tax service, which returns the tax on every
+// line. A jurisdiction the service does not cover is reported as
+// ErrUnsupportedJurisdiction.
+func (c *Client) CalculateTax(ctx context.Context, req Request) (Breakdown, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return Breakdown{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/tax/calculations", bytes.NewBuffer(body))
+	if err != nil {
+		return Breakdown{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return Breakdown{}, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Breakdown{}, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var tax Breakdown
+		if err := json.Unmarshal(respBody, &tax); err != nil {
+			return Breakdown{}, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		if len(tax.Lines) != len(req.Lines) {
+			return Breakdown{}, fmt.Errorf("%w: %d lines for %d", ErrUnexpectedResponse, len(tax.Lines), len(req.Lines))
+		}
+		return tax, nil
+
+	default:
+		return Breakdown{}, statusError(resp.StatusCode, respBody)
+	}
+}
+
+func errorMessage(body []byte, fallback string) string {
+	var resp ErrorResponse
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Message == "" {
+		return fallback
+	}
+	return resp.Message
+}
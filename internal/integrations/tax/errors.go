@@ -0,0 +1,63 @@
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors returned by the calculators, wrapped so that they can be matched with
+// errors.Is. The Client wraps the errors of the tax service in a *StatusError,
+// and returns transport failures such as timeouts as they are.
+var (
+	// ErrUnsupportedJurisdiction is returned for an order the calculator
+	// cannot tax.
+	ErrUnsupportedJurisdiction = errors.New("unsupported tax jurisdiction")
+	// ErrInvalidRequest means the tax service rejected the request.
+	// Retrying the same request will not succeed.
+	ErrInvalidRequest = errors.New("invalid tax request")
+	// ErrUnavailable means the tax service failed to handle the request and
+	// it may succeed if retried.
+	ErrUnavailable = errors.New("tax service unavailable")
+	// ErrUnexpectedResponse means the tax service responded with a status
+	// the client does not handle, or with a breakdown that does not match
+	// the request.
+	ErrUnexpectedResponse = errors.New("unexpected tax service response")
+)
+
+// StatusError is returned when the tax service responds with a status code
+// other than 200 OK.
+type StatusError struct {
+	StatusCode int
+	// Message is the explanation given by the tax service, if any.
+	Message string
+	// Err is the sentinel error the status code maps to.
+	Err error
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s (status: %d)", e.Err, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s (status: %d)", e.Err, e.Message, e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// statusError builds the error for a response status code other than 200 OK.
+func statusError(statusCode int, body []byte) error {
+	err := &StatusError{StatusCode: statusCode, Message: errorMessage(body, "")}
+	switch statusCode {
+	case http.StatusUnprocessableEntity:
+		err.Err = ErrUnsupportedJurisdiction
+	case http.StatusBadRequest:
+		err.Err = ErrInvalidRequest
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		err.Err = ErrUnavailable
+	default:
+		err.Err = ErrUnexpectedResponse
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+// Package tax calculates the tax on orders, either from a table of rates per
+// jurisdiction or with an external tax service.
+package tax
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
+
+// StandardCategory is the tax category of products without one, and of
+// products whose category a jurisdiction has no rate for.
+const StandardCategory = "standard"
+
+type Config struct {
+	// BaseURL is the external tax service. It is used instead of
+	// Jurisdictions if set.
+	BaseURL string `yaml:"baseUrl" validate:"omitempty,http_url"`
+	// Jurisdictions are the tax rates of the places orders are shipped to.
+	// Orders are taxed at processing.taxRate if there are none.
+	Jurisdictions []Jurisdiction `yaml:"jurisdictions" validate:"dive"`
+}
+
+// Jurisdiction is the tax rates of a country, or of a region of it.
+type Jurisdiction struct {
+	// Country is the ISO 3166-1 alpha-2 code of the country.
+	Country string `yaml:"country" validate:"required,iso3166_1_alpha2"`
+	// Region limits the rates to addresses in the region, e.g. "NSW". Rates
+	// of a region take precedence over the rates of its country.
+	Region string `yaml:"region"`
+	// Rates maps tax categories to rates, e.g. "0.10" for 10%. Categories
+	// are compared case-insensitively.
+	Rates map[string]decimal.Decimal `yaml:"rates" validate:"required"`
+}
+
+// name returns the name of the jurisdiction, e.g. "AU-NSW".
+func (j Jurisdiction) name() string {
+	if j.Region == "" {
+		return strings.ToUpper(j.Country)
+	}
+	return strings.ToUpper(j.Country + "-" + j.Region)
+}
+
+// rate returns the rate of category, or the standard rate if the jurisdiction
+// has none for it.
+func (j Jurisdiction) rate(category string) (decimal.Decimal, bool) {
+	if category == "" {
+		category = StandardCategory
+	}
+	if rate, ok := j.Rates[strings.ToLower(category)]; ok {
+		return rate, true
+	}
+	rate, ok := j.Rates[StandardCategory]
+	return rate, ok
+}
+
+// TableCalculator is a temporal.TaxCalculator that taxes orders at the rates
+// of the jurisdiction they are shipped to.
+type TableCalculator struct {
+	jurisdictions map[string]Jurisdiction
+}
+
+// NewTableCalculator checks jurisdictions and returns a calculator that taxes
+// orders with them.
+func NewTableCalculator(jurisdictions []Jurisdiction) (*TableCalculator, error) {
+	validate := validator.New()
+	calculator := &TableCalculator{jurisdictions: make(map[string]Jurisdiction, len(jurisdictions))}
+	for _, j := range jurisdictions {
+		if err := validate.Struct(j); err != nil {
+			return nil, fmt.Errorf("invalid tax jurisdiction %s: %w", j.name(), err)
+		}
+		if _, ok := calculator.jurisdictions[j.name()]; ok {
+			return nil, fmt.Errorf("tax jurisdiction %s is listed more than once", j.name())
+		}
+
+		// Categories are looked up in lower case.
+		rates := make(map[string]decimal.Decimal, len(j.Rates))
+		for category, rate := range j.Rates {
+			if rate.IsNegative() || rate.GreaterThanOrEqual(decimal.NewFromInt(1)) {
+				return nil, fmt.Errorf("tax jurisdiction %s: rate of %s must be between 0 and 1", j.name(), category)
+			}
+			rates[strings.ToLower(category)] = rate
+		}
+		j.Rates = rates
+		calculator.jurisdictions[j.name()] = j
+	}
+	return calculator, nil
+}
+
+// CalculateTax taxes every line of req at the rate of its tax category in the
+// jurisdiction of the address.
+func (c *TableCalculator) CalculateTax(ctx context.Context, req Request) (Breakdown, error) {
+	jurisdiction, err := c.jurisdiction(req.Address)
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	tax := Breakdown{
+		Jurisdiction: jurisdiction.name(),
+		Lines:        make([]LineTax, 0, len(req.Lines)),
+		Total:        decimal.Zero,
+	}
+	for _, line := range req.Lines {
+		rate, ok := jurisdiction.rate(line.TaxCategory)
+		if !ok {
+			return Breakdown{}, fmt.Errorf("%w: %s has no rate for tax category %q", ErrUnsupportedJurisdiction, jurisdiction.name(), line.TaxCategory)
+		}
+		amount := line.Amount.Mul(rate).Round(2)
+		tax.Lines = append(tax.Lines, LineTax{
+			ProductID:   line.ProductID,
+			TaxCategory: line.TaxCategory,
+			Taxable:     line.Amount,
+			Rate:        rate,
+			Amount:      amount,
+		})
+		tax.Total = tax.Total.Add(amount)
+	}
+	return tax, nil
+}
+
+// jurisdiction returns the jurisdiction of the region of address, or else of
+// its country.
+func (c *TableCalculator) jurisdiction(address *Address) (Jurisdiction, error) {
+	if address == nil {
+		return Jurisdiction{}, fmt.Errorf("%w: the order has no address", ErrUnsupportedJurisdiction)
+	}
+
+	region := Jurisdiction{Country: address.Country, Region: address.Region}
+	if j, ok := c.jurisdictions[region.name()]; ok && address.Region != "" {
+		return j, nil
+	}
+	country := Jurisdiction{Country: address.Country}
+	if j, ok := c.jurisdictions[country.name()]; ok {
+		return j, nil
+	}
+	return Jurisdiction{}, fmt.Errorf("%w %s", ErrUnsupportedJurisdiction, region.name())
+}
@@ -0,0 +1,53 @@
+package tax
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Request is the taxable amounts of an order.
+type Request struct {
+	OrderID  uuid.UUID `json:"order_id"`
+	Currency string    `json:"currency"`
+	// Address decides the jurisdiction. It is the shipping address of the
+	// order, or the billing address if the order is not shipped.
+	Address *Address      `json:"address,omitempty"`
+	Lines   []TaxableLine `json:"lines"`
+}
+
+// Address is the address an order is taxed at.
+type Address struct {
+	Line1      string `json:"line1,omitempty"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city,omitempty"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country"`
+}
+
+// TaxableLine is the amount a line item is taxed on.
+type TaxableLine struct {
+	ProductID   uuid.UUID `json:"product_id"`
+	TaxCategory string    `json:"tax_category,omitempty"`
+	// Amount is the price of the line after its discounts and its share of
+	// the order discounts.
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// Breakdown is the tax charged on an order.
+type Breakdown struct {
+	// Jurisdiction names where the tax is charged, e.g. "AU-NSW".
+	Jurisdiction string    `json:"jurisdiction,omitempty"`
+	Lines        []LineTax `json:"lines"`
+	// Total is the sum of the line taxes.
+	Total decimal.Decimal `json:"total"`
+}
+
+// LineTax is the tax charged on a line item.
+type LineTax struct {
+	ProductID   uuid.UUID       `json:"product_id"`
+	TaxCategory string          `json:"tax_category,omitempty"`
+	Taxable     decimal.Decimal `json:"taxable"`
+	Rate        decimal.Decimal `json:"rate"`
+	Amount      decimal.Decimal `json:"amount"`
+}
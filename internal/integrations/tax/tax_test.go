@@ -0,0 +1,264 @@
+package tax_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/tax"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	bread = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	shirt = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+)
+
+func TestTax(t *testing.T) {
+	suite.Run(t, new(TaxTestSuite))
+}
+
+type TaxTestSuite struct {
+	suite.Suite
+}
+
+func rates(rates map[string]string) map[string]decimal.Decimal {
+	m := make(map[string]decimal.Decimal, len(rates))
+	for category, rate := range rates {
+		m[category] = decimal.RequireFromString(rate)
+	}
+	return m
+}
+
+// request returns a request for $10.00 of bread and $20.00 of shirts shipped
+// to address.
+func request(address *tax.Address) tax.Request {
+	return tax.Request{
+		OrderID:  uuid.New(),
+		Currency: "AUD",
+		Address:  address,
+		Lines: []tax.TaxableLine{
+			{ProductID: bread, TaxCategory: "Food", Amount: decimal.RequireFromString("10.00")},
+			{ProductID: shirt, Amount: decimal.RequireFromString("20.00")},
+		},
+	}
+}
+
+func (s *TaxTestSuite) TestTableCalculator_CalculateTax() {
+	jurisdictions := []tax.Jurisdiction{
+		{Country: "AU", Rates: rates(map[string]string{"standard": "0.10", "food": "0"})},
+		{Country: "US", Region: "CA", Rates: rates(map[string]string{"standard": "0.0725"})},
+		{Country: "US", Rates: rates(map[string]string{"standard": "0.05", "food": "0.01"})},
+	}
+
+	tests := []struct {
+		name             string
+		address          *tax.Address
+		wantJurisdiction string
+		wantLines        []string
+		wantTotal        string
+		wantErr          bool
+	}{
+		{
+			name:             "Country rates by category",
+			address:          &tax.Address{Region: "NSW", Country: "AU"},
+			wantJurisdiction: "AU",
+			wantLines:        []string{"0", "2"},
+			wantTotal:        "2",
+		},
+		{
+			name:             "Region rates take precedence",
+			address:          &tax.Address{Region: "ca", Country: "US"},
+			wantJurisdiction: "US-CA",
+			// The region has no food rate, so food is taxed at its
+			// standard rate.
+			wantLines: []string{"0.73", "1.45"},
+			wantTotal: "2.18",
+		},
+		{
+			name:             "Other regions use the country rates",
+			address:          &tax.Address{Region: "NY", Country: "US"},
+			wantJurisdiction: "US",
+			wantLines:        []string{"0.1", "1"},
+			wantTotal:        "1.1",
+		},
+		{
+			name:    "Unsupported country",
+			address: &tax.Address{Country: "NZ"},
+			wantErr: true,
+		},
+		{
+			name:    "No address",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			calculator, err := tax.NewTableCalculator(jurisdictions)
+			s.Require().NoError(err)
+
+			// Invoke
+			got, err := calculator.CalculateTax(context.Background(), request(tt.address))
+
+			// Assert
+			if tt.wantErr {
+				s.Require().ErrorIs(err, tax.ErrUnsupportedJurisdiction)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(tt.wantJurisdiction, got.Jurisdiction)
+			s.Require().Len(got.Lines, len(tt.wantLines))
+			for i, want := range tt.wantLines {
+				s.Equal(want, got.Lines[i].Amount.String(), "line %d", i)
+			}
+			s.Equal(tt.wantTotal, got.Total.String())
+		})
+	}
+}
+
+func (s *TaxTestSuite) TestTableCalculator_NoRateForCategory() {
+	// Setup
+	calculator, err := tax.NewTableCalculator([]tax.Jurisdiction{
+		{Country: "AU", Rates: rates(map[string]string{"food": "0"})},
+	})
+	s.Require().NoError(err)
+
+	// Invoke
+	_, err = calculator.CalculateTax(context.Background(), request(&tax.Address{Country: "AU"}))
+
+	// Assert
+	s.Require().ErrorIs(err, tax.ErrUnsupportedJurisdiction)
+	s.ErrorContains(err, `tax category ""`)
+}
+
+func (s *TaxTestSuite) TestNewTableCalculator_InvalidJurisdictions() {
+	tests := []struct {
+		name          string
+		jurisdictions []tax.Jurisdiction
+		err           string
+	}{
+		{
+			name:          "Unknown country",
+			jurisdictions: []tax.Jurisdiction{{Country: "XX", Rates: rates(map[string]string{"standard": "0.10"})}},
+			err:           "iso3166_1_alpha2",
+		},
+		{
+			name:          "Rate given as a percentage",
+			jurisdictions: []tax.Jurisdiction{{Country: "AU", Rates: rates(map[string]string{"standard": "10"})}},
+			err:           "rate of standard must be between 0 and 1",
+		},
+		{
+			name: "Listed twice",
+			jurisdictions: []tax.Jurisdiction{
+				{Country: "AU", Region: "NSW", Rates: rates(map[string]string{"standard": "0.10"})},
+				{Country: "AU", Region: "nsw", Rates: rates(map[string]string{"standard": "0.10"})},
+			},
+			err: "tax jurisdiction AU-NSW is listed more than once",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			_, err := tax.NewTableCalculator(tt.jurisdictions)
+
+			s.Require().ErrorContains(err, tt.err)
+		})
+	}
+}
+
+func (s *TaxTestSuite) TestClient_CalculateTax() {
+	tests := []struct {
+		name       string
+		statusCode int
+		response   any
+		wantTotal  string
+		wantErr    error
+		err        string
+	}{
+		{
+			name:       "Taxed",
+			statusCode: http.StatusOK,
+			response: tax.Breakdown{
+				Jurisdiction: "AU",
+				Lines: []tax.LineTax{
+					{ProductID: bread, Amount: decimal.Zero},
+					{ProductID: shirt, Amount: decimal.RequireFromString("2.00")},
+				},
+				Total: decimal.RequireFromString("2.00"),
+			},
+			wantTotal: "2",
+		},
+		{
+			name:       "Unsupported jurisdiction",
+			statusCode: http.StatusUnprocessableEntity,
+			response:   tax.ErrorResponse{Message: "no nexus in AU"},
+			wantErr:    tax.ErrUnsupportedJurisdiction,
+			err:        "no nexus in AU",
+		},
+		{
+			name:       "Invalid request",
+			statusCode: http.StatusBadRequest,
+			response:   tax.ErrorResponse{Message: "currency is required"},
+			wantErr:    tax.ErrInvalidRequest,
+			err:        "invalid tax request: currency is required (status: 400)",
+		},
+		{
+			name:       "Service unavailable",
+			statusCode: http.StatusServiceUnavailable,
+			wantErr:    tax.ErrUnavailable,
+			err:        "tax service unavailable (status: 503)",
+		},
+		{
+			name:       "Unexpected status",
+			statusCode: http.StatusTeapot,
+			wantErr:    tax.ErrUnexpectedResponse,
+			err:        "status: 418",
+		},
+		{
+			name:       "Missing lines",
+			statusCode: http.StatusOK,
+			response:   tax.Breakdown{Jurisdiction: "AU", Lines: []tax.LineTax{{ProductID: bread}}},
+			wantErr:    tax.ErrUnexpectedResponse,
+			err:        "1 lines for 2",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				s.Equal("/tax/calculations", r.URL.Path)
+				var req tax.Request
+				s.NoError(json.NewDecoder(r.Body).Decode(&req))
+				s.Len(req.Lines, 2)
+
+				w.WriteHeader(tt.statusCode)
+				if tt.response != nil {
+					s.NoError(json.NewEncoder(w).Encode(tt.response))
+				}
+			}))
+			defer server.Close()
+
+			// Invoke
+			got, err := tax.NewClient(server.URL).CalculateTax(context.Background(), request(&tax.Address{Country: "AU"}))
+
+			// Assert
+			if tt.err != "" {
+				s.Require().ErrorContains(err, tt.err)
+				if tt.wantErr != nil {
+					s.ErrorIs(err, tt.wantErr)
+				}
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal("AU", got.Jurisdiction)
+			s.Equal(tt.wantTotal, got.Total.String())
+		})
+	}
+}
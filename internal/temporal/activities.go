@@ -1,11 +1,16 @@
 package temporal
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/payment"
 	"github.com/shopspring/decimal"
+	"go.temporal.io/sdk/activity"
 	"go.temporal.io/sdk/temporal"
 )
 
@@ -13,56 +18,417 @@ type InventoryChecker interface {
 	CheckInventory(context.Context, uuid.UUID, int32) (bool, error)
 }
 
+// BatchInventoryChecker checks the stock of every product in an order at
+// once.
+type BatchInventoryChecker interface {
+	// CheckInventoryBatch reports for each product whether the requested
+	// quantity is available.
+	CheckInventoryBatch(ctx context.Context, items map[uuid.UUID]int32) (map[uuid.UUID]bool, error)
+}
+
+// InventoryReserver holds stock for an order between validation and
+// processing so that concurrent orders cannot oversell it.
+type InventoryReserver interface {
+	ReserveInventory(ctx context.Context, orderID uuid.UUID, items map[uuid.UUID]int32, ttl time.Duration) (bool, error)
+	CommitReservation(ctx context.Context, orderID uuid.UUID) error
+	ReleaseReservation(ctx context.Context, orderID uuid.UUID) error
+	// RestockInventory returns committed stock to inventory. Restocking
+	// adds to the stock level, so a request repeated with the same
+	// idempotencyKey must only be applied once.
+	RestockInventory(ctx context.Context, orderID uuid.UUID, items map[uuid.UUID]int32, idempotencyKey string) error
+}
+
+// PaymentGateway takes payment for an order. Payments are referenced by order
+// ID so that every call is safe to retry. Failures the gateway reports are
+// returned wrapping one of the payment package's errors.
+type PaymentGateway interface {
+	// AuthorizePayment places a hold on the customer's funds for the order. A
+	// declined payment is reported through the returned authorization rather
+	// than as an error.
+	AuthorizePayment(ctx context.Context, orderID uuid.UUID, amount decimal.Decimal) (PaymentAuthorization, error)
+	CapturePayment(ctx context.Context, orderID uuid.UUID, amount decimal.Decimal) error
+	// VoidPayment releases an authorization that has not been captured.
+	// Voiding a payment that was never authorized is not an error.
+	VoidPayment(ctx context.Context, orderID uuid.UUID) error
+	RefundPayment(ctx context.Context, orderID uuid.UUID, amount decimal.Decimal) error
+}
+
+// EscalationNotifier alerts the people responsible for an order that has
+// breached its SLA.
+type EscalationNotifier interface {
+	NotifyEscalation(ctx context.Context, escalation Escalation) error
+}
+
+// OrderRepository persists processed orders.
+type OrderRepository interface {
+	// SaveOrder stores the record, replacing any existing record for the
+	// same order so that retries are safe.
+	SaveOrder(ctx context.Context, record OrderRecord) error
+	// VoidOrder marks the stored record of an order that will not be
+	// fulfilled. Voiding an order that was never saved is not an error.
+	VoidOrder(ctx context.Context, orderID uuid.UUID) error
+}
+
 type OrderActivities struct {
-	inventoryClient InventoryChecker
+	inventoryClient    InventoryChecker
+	batchChecker       BatchInventoryChecker
+	inventoryReserver  InventoryReserver
+	paymentGateway     PaymentGateway
+	escalationNotifier EscalationNotifier
+	orderRepository    OrderRepository
+	pricingEngine      PricingEngine
+	taxCalculator      TaxCalculator
+	fraudScorer        FraudScorer
+	processingConfig   ProcessingConfig
+	routing            RoutingStrategy
+	warehouses         []Warehouse
+}
+
+// ActivityOption configures optional dependencies of OrderActivities.
+type ActivityOption func(*OrderActivities)
+
+// WithBatchInventoryChecker sets the client Validate uses to check every line
+// item in one call instead of one call per item.
+func WithBatchInventoryChecker(checker BatchInventoryChecker) ActivityOption {
+	return func(a *OrderActivities) {
+		a.batchChecker = checker
+	}
+}
+
+// WithInventoryReserver sets the client used to reserve, commit and release
+// stock for an order.
+func WithInventoryReserver(reserver InventoryReserver) ActivityOption {
+	return func(a *OrderActivities) {
+		a.inventoryReserver = reserver
+	}
+}
+
+// WithPaymentGateway sets the gateway used to authorize and capture payment
+// for an order.
+func WithPaymentGateway(gateway PaymentGateway) ActivityOption {
+	return func(a *OrderActivities) {
+		a.paymentGateway = gateway
+	}
+}
+
+// WithEscalationNotifier sets where SLA breaches are reported in addition to
+// the worker log.
+func WithEscalationNotifier(notifier EscalationNotifier) ActivityOption {
+	return func(a *OrderActivities) {
+		a.escalationNotifier = notifier
+	}
+}
+
+// WithOrderRepository sets where processed orders are persisted.
+func WithOrderRepository(repository OrderRepository) ActivityOption {
+	return func(a *OrderActivities) {
+		a.orderRepository = repository
+	}
+}
+
+// WithProcessingConfig sets the tax and shipping charges applied when an order
+// is processed. No charges are applied by default.
+func WithProcessingConfig(cfg ProcessingConfig) ActivityOption {
+	return func(a *OrderActivities) {
+		a.processingConfig = cfg
+	}
 }
 
-func NewOrderActivities(inventoryClient InventoryChecker) *OrderActivities {
-	return &OrderActivities{
+func NewOrderActivities(inventoryClient InventoryChecker, opts ...ActivityOption) *OrderActivities {
+	a := &OrderActivities{
 		inventoryClient: inventoryClient,
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// PaymentAuthorization is the gateway's response to a payment authorization.
+type PaymentAuthorization = payment.Authorization
+
+// Validate checks that the order is well formed and consistent, and that
+// every line item is in stock. If warehouses are configured it returns the
+// warehouse each line item is allocated to, in line item order.
+func (a *OrderActivities) Validate(ctx context.Context, order Order) ([]Allocation, error) {
+	if err := checkOrder(order); err != nil {
+		return nil, err
+	}
+
+	quantities := quantitiesByProduct(order.LineItems)
+
+	var allocations []Allocation
+	var unavailable []uuid.UUID
+	if len(a.warehouses) > 0 {
+		var err error
+		allocations, unavailable, err = a.allocate(ctx, order, quantities)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		available, err := checkInventory(ctx, a.inventoryClient, a.batchChecker, quantities, "")
+		if err != nil {
+			return nil, err
+		}
+
+		// Report every unavailable product rather than just the first.
+		for _, productID := range sortedProductIDs(quantities) {
+			if !available[productID] {
+				unavailable = append(unavailable, productID)
+			}
+		}
+	}
+
+	if len(unavailable) > 0 {
+		return nil, temporal.NewNonRetryableApplicationError(
+			"insufficient inventory for products",
+			InsufficientInventoryErrorType,
+			fmt.Errorf("insufficient inventory for products %v", unavailable),
+			unavailable,
+		)
+	}
+
+	return allocations, nil
+}
+
+// checkInventory checks the stock of every product, in one call if batch is
+// not nil. Errors name the warehouse checked, if any.
+func checkInventory(ctx context.Context, checker InventoryChecker, batch BatchInventoryChecker, items map[uuid.UUID]int32, warehouse string) (map[uuid.UUID]bool, error) {
+	if batch != nil {
+		available, err := batch.CheckInventoryBatch(ctx, items)
+		if err != nil {
+			return nil, inventoryError("failed to check inventory"+inWarehouse(warehouse), err)
+		}
+		return available, nil
+	}
+
+	available := make(map[uuid.UUID]bool, len(items))
+	for _, productID := range sortedProductIDs(items) {
+		ok, err := checker.CheckInventory(ctx, productID, items[productID])
+		if err != nil {
+			return nil, inventoryError(fmt.Sprintf("failed to check inventory for product %s%s", productID, inWarehouse(warehouse)), err)
+		}
+		available[productID] = ok
+	}
+	return available, nil
+}
+
+// Process totals the priced and taxed order, adds shipping and persists the
+// result.
+func (a *OrderActivities) Process(ctx context.Context, order Order, pricing PriceBreakdown, tax TaxBreakdown) (ProcessingResult, error) {
+	result := a.processingConfig.calculateTotals(order, pricing, tax)
+	result.ProcessedAt = time.Now().UTC()
+
+	err := a.orderRepository.SaveOrder(ctx, OrderRecord{
+		Order:      order,
+		Pricing:    pricing,
+		Tax:        tax,
+		Processing: result,
+	})
+	if err != nil {
+		return ProcessingResult{}, fmt.Errorf("failed to save order %s: %w", order.ID, err)
+	}
+
+	return result, nil
 }
 
-type Order struct {
-	ID        uuid.UUID  `json:"id"`
-	LineItems []LineItem `json:"line_items"`
+// VoidOrder marks the persisted record of an order that will not be fulfilled.
+func (a *OrderActivities) VoidOrder(ctx context.Context, orderID uuid.UUID) error {
+	if err := a.orderRepository.VoidOrder(ctx, orderID); err != nil {
+		return fmt.Errorf("failed to void order %s: %w", orderID, err)
+	}
+	return nil
 }
 
-type LineItem struct {
-	ProductID    uuid.UUID       `json:"product_id"`
-	Quantity     int32           `json:"quantity"`
-	PricePerItem decimal.Decimal `json:"price_per_item"`
+// ReserveInventory holds stock for every line item in the order for the given
+// TTL, in the warehouse each item was allocated to. Without allocations the
+// stock is reserved with the default reserver.
+func (a *OrderActivities) ReserveInventory(ctx context.Context, order Order, allocations []Allocation, ttl time.Duration) error {
+	if len(allocations) == 0 {
+		return a.reserve(ctx, a.inventoryReserver, order.ID, quantitiesByProduct(order.LineItems), ttl, "")
+	}
+
+	byWarehouse := allocatedQuantities(allocations)
+	for _, warehouse := range allocatedWarehouses(allocations) {
+		reserver, err := a.reserverFor(warehouse)
+		if err != nil {
+			return err
+		}
+		if err := a.reserve(ctx, reserver, order.ID, byWarehouse[warehouse], ttl, warehouse); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (a *OrderActivities) Validate(ctx context.Context, order Order) error {
-	if (order.ID == uuid.UUID{}) {
-		return fmt.Errorf("order must have a valid order ID")
+func (a *OrderActivities) reserve(ctx context.Context, reserver InventoryReserver, orderID uuid.UUID, items map[uuid.UUID]int32, ttl time.Duration, warehouse string) error {
+	reserved, err := reserver.ReserveInventory(ctx, orderID, items, ttl)
+	if err != nil {
+		return inventoryError(fmt.Sprintf("failed to reserve inventory for order %s%s", orderID, inWarehouse(warehouse)), err)
+	}
+	if !reserved {
+		return temporal.NewNonRetryableApplicationError(
+			"insufficient inventory to reserve order",
+			InsufficientInventoryErrorType,
+			fmt.Errorf("insufficient inventory to reserve order %s%s", orderID, inWarehouse(warehouse)),
+		)
 	}
 
-	if len(order.LineItems) < 1 {
-		return fmt.Errorf("order must have at least one item")
+	return nil
+}
+
+// CommitInventory permanently deducts the stock reserved for the order in
+// every warehouse it was allocated to.
+func (a *OrderActivities) CommitInventory(ctx context.Context, orderID uuid.UUID, allocations []Allocation) error {
+	return a.eachReserver(allocations, func(reserver InventoryReserver, warehouse string) error {
+		if err := reserver.CommitReservation(ctx, orderID); err != nil {
+			return inventoryError(fmt.Sprintf("failed to commit inventory reservation for order %s%s", orderID, inWarehouse(warehouse)), err)
+		}
+		return nil
+	})
+}
+
+// ReleaseInventory returns the stock reserved for the order in every
+// warehouse it was allocated to.
+func (a *OrderActivities) ReleaseInventory(ctx context.Context, orderID uuid.UUID, allocations []Allocation) error {
+	return a.eachReserver(allocations, func(reserver InventoryReserver, warehouse string) error {
+		if err := reserver.ReleaseReservation(ctx, orderID); err != nil {
+			return inventoryError(fmt.Sprintf("failed to release inventory reservation for order %s%s", orderID, inWarehouse(warehouse)), err)
+		}
+		return nil
+	})
+}
+
+// RestockInventory returns the given line items of an order to inventory after
+// their stock has been committed, each to the warehouse it was allocated to.
+func (a *OrderActivities) RestockInventory(ctx context.Context, orderID uuid.UUID, items []LineItem, allocations []Allocation) error {
+	byWarehouse := map[string]map[uuid.UUID]int32{"": quantitiesByProduct(items)}
+	if len(allocations) > 0 {
+		byWarehouse = restockQuantities(items, allocations)
 	}
 
-	// Check inventory for each line item
-	for _, item := range order.LineItems {
-		available, err := a.inventoryClient.CheckInventory(ctx, item.ProductID, item.Quantity)
+	return a.eachReserver(allocations, func(reserver InventoryReserver, warehouse string) error {
+		quantities := byWarehouse[warehouse]
+		if len(quantities) == 0 {
+			return nil
+		}
+		if err := reserver.RestockInventory(ctx, orderID, quantities, restockKey(orderID, warehouse)); err != nil {
+			return inventoryError(fmt.Sprintf("failed to restock inventory for order %s%s", orderID, inWarehouse(warehouse)), err)
+		}
+		return nil
+	})
+}
+
+// restockKey is the idempotency key of the restock of an order in a
+// warehouse, so that restocks repeated when RestockInventory is retried after
+// another warehouse failed are only applied once.
+func restockKey(orderID uuid.UUID, warehouse string) string {
+	if warehouse == "" {
+		return orderID.String() + "-restock"
+	}
+	return orderID.String() + "-restock-" + warehouse
+}
+
+// eachReserver calls fn with the reserver of every warehouse in allocations,
+// or with the default reserver and no warehouse if there are none. Calls that
+// succeeded are repeated when the activity is retried, so fn must be
+// idempotent.
+func (a *OrderActivities) eachReserver(allocations []Allocation, fn func(reserver InventoryReserver, warehouse string) error) error {
+	if len(allocations) == 0 {
+		return fn(a.inventoryReserver, "")
+	}
+
+	for _, warehouse := range allocatedWarehouses(allocations) {
+		reserver, err := a.reserverFor(warehouse)
 		if err != nil {
-			return fmt.Errorf("failed to check inventory for product %s: %w", item.ProductID, err)
+			return err
 		}
-		if !available {
-			return temporal.NewNonRetryableApplicationError(
-				"insufficient inventory for product",
-				"validation",
-				fmt.Errorf("insufficient inventory for product %s", item.ProductID),
-			)
+		if err := fn(reserver, warehouse); err != nil {
+			return err
 		}
 	}
+	return nil
+}
+
+// AuthorizePayment places a hold for the total of the priced and taxed order,
+// including shipping, on the customer's funds.
+func (a *OrderActivities) AuthorizePayment(ctx context.Context, order Order, pricing PriceBreakdown, tax TaxBreakdown) (PaymentAuthorization, error) {
+	amount := a.processingConfig.calculateTotals(order, pricing, tax).Total
+
+	authorization, err := a.paymentGateway.AuthorizePayment(ctx, order.ID, amount)
+	if err != nil {
+		return PaymentAuthorization{}, paymentError(fmt.Sprintf("failed to authorize payment for order %s", order.ID), err)
+	}
+	if !authorization.Approved {
+		return PaymentAuthorization{}, temporal.NewNonRetryableApplicationError(
+			"payment declined",
+			PaymentDeclinedErrorType,
+			fmt.Errorf("payment declined for order %s: %s", order.ID, authorization.DeclineReason),
+		)
+	}
+
+	return authorization, nil
+}
+
+// CapturePayment takes the authorized amount from the customer.
+func (a *OrderActivities) CapturePayment(ctx context.Context, orderID uuid.UUID, amount decimal.Decimal) error {
+	if err := a.paymentGateway.CapturePayment(ctx, orderID, amount); err != nil {
+		return paymentError(fmt.Sprintf("failed to capture payment for order %s", orderID), err)
+	}
+	return nil
+}
+
+// VoidPayment releases the hold on the customer's funds for the order.
+func (a *OrderActivities) VoidPayment(ctx context.Context, orderID uuid.UUID) error {
+	if err := a.paymentGateway.VoidPayment(ctx, orderID); err != nil {
+		return paymentError(fmt.Sprintf("failed to void payment for order %s", orderID), err)
+	}
+	return nil
+}
+
+// RefundPayment returns a captured amount to the customer.
+func (a *OrderActivities) RefundPayment(ctx context.Context, orderID uuid.UUID, amount decimal.Decimal) error {
+	if err := a.paymentGateway.RefundPayment(ctx, orderID, amount); err != nil {
+		return paymentError(fmt.Sprintf("failed to refund payment for order %s", orderID), err)
+	}
+	return nil
+}
+
+// Escalate reports an order that has stayed in a stage past its SLA.
+func (a *OrderActivities) Escalate(ctx context.Context, escalation Escalation) error {
+	activity.GetLogger(ctx).Warn("Order breached SLA",
+		"orderID", escalation.OrderID,
+		"stage", escalation.Stage,
+		"deadline", escalation.Deadline,
+		"enteredAt", escalation.EnteredAt,
+		"autoCancel", escalation.AutoCancel,
+	)
 
+	if a.escalationNotifier == nil {
+		return nil
+	}
+	if err := a.escalationNotifier.NotifyEscalation(ctx, escalation); err != nil {
+		return fmt.Errorf("failed to notify escalation for order %s: %w", escalation.OrderID, err)
+	}
 	return nil
 }
 
-func (a *OrderActivities) Process(ctx context.Context, order Order) (string, error) {
-	// TODO: add order processing logic.
+// sortedProductIDs returns the keys of m in a stable order.
+func sortedProductIDs[V any](m map[uuid.UUID]V) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int {
+		return bytes.Compare(a[:], b[:])
+	})
+	return ids
+}
 
-	return "Processed", nil
+func quantitiesByProduct(items []LineItem) map[uuid.UUID]int32 {
+	quantities := make(map[uuid.UUID]int32, len(items))
+	for _, item := range items {
+		quantities[item.ProductID] += item.Quantity
+	}
+	return quantities
 }
@@ -1,15 +1,23 @@
 package temporal_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/inventory"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/payment"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/tax"
 	"github.com/pulinau/demo-temporal-order-processor/internal/temporal"
 	temporalmocks "github.com/pulinau/demo-temporal-order-processor/internal/temporal/mocks"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	sdktemporal "go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/testsuite"
 )
 
@@ -42,7 +50,8 @@ func (s *ActivityTestSuite) TestValidate_Success() {
 
 	// Invoke
 	_, err := s.env.ExecuteActivity(activities.Validate, temporal.Order{
-		ID: uuid.MustParse(dummyOrderID),
+		ID:       uuid.MustParse(dummyOrderID),
+		Currency: "AUD",
 		LineItems: []temporal.LineItem{
 			{
 				ProductID:    uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721"),
@@ -67,7 +76,7 @@ func (s *ActivityTestSuite) TestValidate_Fail() {
 		{
 			name:  "Missing order ID",
 			input: temporal.Order{},
-			err:   "order must have a valid order ID",
+			err:   "id must be a non-nil UUID",
 		},
 		{
 			name: "No items in order",
@@ -75,12 +84,13 @@ func (s *ActivityTestSuite) TestValidate_Fail() {
 				ID:        uuid.MustParse(dummyOrderID),
 				LineItems: []temporal.LineItem{},
 			},
-			err: "order must have at least one item",
+			err: "line_items must have at least 1 items",
 		},
 		{
 			name: "No inventory for line item",
 			input: temporal.Order{
-				ID: uuid.MustParse(dummyOrderID),
+				ID:       uuid.MustParse(dummyOrderID),
+				Currency: "AUD",
 				LineItems: []temporal.LineItem{
 					{
 						ProductID:    uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721"),
@@ -97,7 +107,8 @@ func (s *ActivityTestSuite) TestValidate_Fail() {
 		{
 			name: "Inventory checker error",
 			input: temporal.Order{
-				ID: uuid.MustParse(dummyOrderID),
+				ID:       uuid.MustParse(dummyOrderID),
+				Currency: "AUD",
 				LineItems: []temporal.LineItem{
 					{
 						ProductID:    uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721"),
@@ -132,3 +143,1388 @@ func (s *ActivityTestSuite) TestValidate_Fail() {
 		})
 	}
 }
+
+func (s *ActivityTestSuite) TestReserveInventory() {
+	order := temporal.Order{
+		ID: uuid.MustParse(dummyOrderID),
+		LineItems: []temporal.LineItem{
+			{
+				ProductID:    uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721"),
+				Quantity:     1,
+				PricePerItem: decimal.RequireFromString("123.45"),
+			},
+			{
+				ProductID:    uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721"),
+				Quantity:     2,
+				PricePerItem: decimal.RequireFromString("123.45"),
+			},
+		},
+	}
+	wantItems := map[uuid.UUID]int32{
+		uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721"): 3,
+	}
+
+	tests := []struct {
+		name     string
+		reserved bool
+		err      error
+		wantErr  string
+	}{
+		{
+			name:     "Reserved",
+			reserved: true,
+		},
+		{
+			name:     "Insufficient inventory",
+			reserved: false,
+			wantErr:  "insufficient inventory to reserve order",
+		},
+		{
+			name:    "Inventory reserver error",
+			err:     errors.New("test error"),
+			wantErr: "failed to reserve inventory for order",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			inventoryReserver := temporalmocks.NewMockInventoryReserver(s.T())
+			inventoryReserver.EXPECT().
+				ReserveInventory(mock.Anything, uuid.MustParse(dummyOrderID), wantItems, time.Hour).
+				Return(tt.reserved, tt.err)
+
+			activities := temporal.NewOrderActivities(nil, temporal.WithInventoryReserver(inventoryReserver))
+			s.env.RegisterActivity(activities.ReserveInventory)
+
+			// Invoke
+			_, err := s.env.ExecuteActivity(activities.ReserveInventory, order, []temporal.Allocation(nil), time.Hour)
+
+			// Assert
+			if tt.wantErr == "" {
+				s.Require().NoError(err)
+				return
+			}
+			s.Require().ErrorContains(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *ActivityTestSuite) TestReleaseInventory_Error() {
+	// Setup
+	inventoryReserver := temporalmocks.NewMockInventoryReserver(s.T())
+	inventoryReserver.EXPECT().
+		ReleaseReservation(mock.Anything, uuid.MustParse(dummyOrderID)).
+		Return(errors.New("test error"))
+
+	activities := temporal.NewOrderActivities(nil, temporal.WithInventoryReserver(inventoryReserver))
+	s.env.RegisterActivity(activities.ReleaseInventory)
+
+	// Invoke
+	_, err := s.env.ExecuteActivity(activities.ReleaseInventory, uuid.MustParse(dummyOrderID), []temporal.Allocation(nil))
+
+	// Assert
+	s.Require().ErrorContains(err, "failed to release inventory reservation for order")
+}
+
+func (s *ActivityTestSuite) TestEscalate() {
+	escalation := temporal.Escalation{
+		OrderID:  uuid.MustParse(dummyOrderID),
+		Stage:    temporal.Placed,
+		Deadline: 24 * time.Hour,
+	}
+
+	tests := []struct {
+		name       string
+		setupMocks func(mockEN *temporalmocks.MockEscalationNotifier)
+		wantErr    string
+	}{
+		{
+			name: "Notified",
+			setupMocks: func(mockEN *temporalmocks.MockEscalationNotifier) {
+				mockEN.EXPECT().NotifyEscalation(mock.Anything, escalation).Return(nil)
+			},
+		},
+		{
+			name: "Notifier error",
+			setupMocks: func(mockEN *temporalmocks.MockEscalationNotifier) {
+				mockEN.EXPECT().NotifyEscalation(mock.Anything, escalation).Return(errors.New("test error"))
+			},
+			wantErr: "failed to notify escalation for order",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			notifier := temporalmocks.NewMockEscalationNotifier(s.T())
+			tt.setupMocks(notifier)
+
+			activities := temporal.NewOrderActivities(nil, temporal.WithEscalationNotifier(notifier))
+			s.env.RegisterActivity(activities.Escalate)
+
+			// Invoke
+			_, err := s.env.ExecuteActivity(activities.Escalate, escalation)
+
+			// Assert
+			if tt.wantErr == "" {
+				s.Require().NoError(err)
+				return
+			}
+			s.Require().ErrorContains(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *ActivityTestSuite) TestProcess() {
+	order := temporal.Order{
+		ID: uuid.MustParse(dummyOrderID),
+		LineItems: []temporal.LineItem{
+			{
+				ProductID:    uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721"),
+				Quantity:     2,
+				PricePerItem: decimal.RequireFromString("19.99"),
+			},
+			{
+				ProductID:    uuid.MustParse("5a1c1f6e-0c43-4c1c-9a55-1b7f0f5c2c1e"),
+				Quantity:     3,
+				PricePerItem: decimal.RequireFromString("0.333"),
+			},
+		},
+	}
+	cfg := temporal.ProcessingConfig{
+		TaxRate:               decimal.RequireFromString("0.0825"),
+		ShippingFee:           decimal.RequireFromString("4.99"),
+		FreeShippingThreshold: decimal.RequireFromString("100"),
+	}
+
+	tests := []struct {
+		name         string
+		cfg          temporal.ProcessingConfig
+		wantSubtotal string
+		wantTax      string
+		wantShipping string
+		wantTotal    string
+	}{
+		{
+			name:         "Tax and shipping applied",
+			cfg:          cfg,
+			wantSubtotal: "40.98",
+			wantTax:      "3.38",
+			wantShipping: "4.99",
+			wantTotal:    "49.35",
+		},
+		{
+			name: "Free shipping over threshold",
+			cfg: temporal.ProcessingConfig{
+				TaxRate:               cfg.TaxRate,
+				ShippingFee:           cfg.ShippingFee,
+				FreeShippingThreshold: decimal.RequireFromString("40"),
+			},
+			wantSubtotal: "40.98",
+			wantTax:      "3.38",
+			wantShipping: "0",
+			wantTotal:    "44.36",
+		},
+		{
+			name:         "No charges configured",
+			cfg:          temporal.ProcessingConfig{},
+			wantSubtotal: "40.98",
+			wantTax:      "0",
+			wantShipping: "0",
+			wantTotal:    "40.98",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			orderRepository := temporalmocks.NewMockOrderRepository(s.T())
+			orderRepository.EXPECT().
+				SaveOrder(mock.Anything, mock.MatchedBy(func(r temporal.OrderRecord) bool {
+					return r.Order.ID == order.ID && r.Processing.Total.Equal(decimal.RequireFromString(tt.wantTotal))
+				})).
+				Return(nil)
+
+			activities := temporal.NewOrderActivities(nil,
+				temporal.WithOrderRepository(orderRepository),
+				temporal.WithProcessingConfig(tt.cfg),
+			)
+			s.env.RegisterActivity(activities.Process)
+
+			// Invoke
+			pricing := pricedAsGiven(order)
+			val, err := s.env.ExecuteActivity(activities.Process, order, pricing, taxedAt(pricing, tt.cfg.TaxRate))
+
+			// Assert
+			s.Require().NoError(err)
+			var got temporal.ProcessingResult
+			s.Require().NoError(val.Get(&got))
+			s.Equal(order.ID, got.OrderID)
+			s.Require().Len(got.Lines, 2)
+			s.Equal("39.98", got.Lines[0].Total.String())
+			s.Equal("1", got.Lines[1].Total.String())
+			s.Equal(tt.wantTax, got.Lines[0].Tax.Add(got.Lines[1].Tax).String())
+			s.Equal(tt.wantSubtotal, got.Subtotal.String())
+			s.Equal(tt.wantTax, got.Tax.String())
+			s.Equal(tt.wantShipping, got.Shipping.String())
+			s.Equal(tt.wantTotal, got.Total.String())
+			s.False(got.ProcessedAt.IsZero())
+		})
+	}
+}
+
+func (s *ActivityTestSuite) TestProcess_RepositoryError() {
+	// Setup
+	orderRepository := temporalmocks.NewMockOrderRepository(s.T())
+	orderRepository.EXPECT().SaveOrder(mock.Anything, mock.Anything).Return(errors.New("test error"))
+
+	activities := temporal.NewOrderActivities(nil, temporal.WithOrderRepository(orderRepository))
+	s.env.RegisterActivity(activities.Process)
+
+	// Invoke
+	_, err := s.env.ExecuteActivity(activities.Process, temporal.Order{ID: uuid.MustParse(dummyOrderID)}, temporal.PriceBreakdown{}, temporal.TaxBreakdown{})
+
+	// Assert
+	s.Require().ErrorContains(err, "failed to save order")
+}
+
+func (s *ActivityTestSuite) TestProcess_Discounts() {
+	// Setup
+	productA := uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721")
+	productB := uuid.MustParse("5a1c1f6e-0c43-4c1c-9a55-1b7f0f5c2c1e")
+	order := temporal.Order{ID: uuid.MustParse(dummyOrderID), Currency: "AUD"}
+	pricing := temporal.PriceBreakdown{
+		Lines: []temporal.LinePrice{
+			{
+				ProductID: productA,
+				Quantity:  3,
+				UnitPrice: decimal.RequireFromString("20.00"),
+				Discounts: []temporal.Discount{{Promotion: "3-for-50", Amount: decimal.RequireFromString("10.00")}},
+				Total:     decimal.RequireFromString("50.00"),
+			},
+			{
+				ProductID: productB,
+				Quantity:  1,
+				UnitPrice: decimal.RequireFromString("50.00"),
+				Total:     decimal.RequireFromString("50.00"),
+			},
+		},
+		Discounts:    []temporal.Discount{{Promotion: "SPRING10", Amount: decimal.RequireFromString("10.00")}},
+		FreeShipping: true,
+		Coupons:      []string{"SPRING10"},
+	}
+	// Each line is taxed on 45.00, after its share of the order discount.
+	tax := temporal.TaxBreakdown{
+		Jurisdiction: "AU",
+		Lines: []temporal.LineTax{
+			{ProductID: productA, Taxable: decimal.RequireFromString("45.00"), Rate: decimal.RequireFromString("0.10"), Amount: decimal.RequireFromString("4.50")},
+			{ProductID: productB, Taxable: decimal.RequireFromString("45.00"), Rate: decimal.RequireFromString("0.10"), Amount: decimal.RequireFromString("4.50")},
+		},
+		Total: decimal.RequireFromString("9.00"),
+	}
+
+	orderRepository := temporalmocks.NewMockOrderRepository(s.T())
+	orderRepository.EXPECT().
+		SaveOrder(mock.Anything, mock.MatchedBy(func(r temporal.OrderRecord) bool {
+			return r.Order.ID == order.ID && slices.Equal(r.Pricing.Coupons, pricing.Coupons) && r.Tax.Jurisdiction == "AU"
+		})).
+		Return(nil)
+
+	activities := temporal.NewOrderActivities(nil,
+		temporal.WithOrderRepository(orderRepository),
+		temporal.WithProcessingConfig(temporal.ProcessingConfig{
+			ShippingFee: decimal.RequireFromString("4.99"),
+		}),
+	)
+	s.env.RegisterActivity(activities.Process)
+
+	// Invoke
+	val, err := s.env.ExecuteActivity(activities.Process, order, pricing, tax)
+
+	// Assert
+	s.Require().NoError(err)
+	var got temporal.ProcessingResult
+	s.Require().NoError(val.Get(&got))
+	s.Require().Len(got.Lines, 2)
+	s.Equal("10", got.Lines[0].Discount.String())
+	s.Equal("50", got.Lines[0].Total.String())
+	s.Equal("4.5", got.Lines[0].Tax.String())
+	s.Equal("100", got.Subtotal.String())
+	s.Equal("10", got.Discount.String())
+	s.Equal("9", got.Tax.String())
+	s.Equal("AU", got.TaxJurisdiction)
+	s.Equal("0", got.Shipping.String())
+	s.Equal("99", got.Total.String())
+}
+
+func (s *ActivityTestSuite) TestAuthorizePayment() {
+	order := temporal.Order{
+		ID: uuid.MustParse(dummyOrderID),
+		LineItems: []temporal.LineItem{
+			{
+				ProductID:    uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721"),
+				Quantity:     2,
+				PricePerItem: decimal.RequireFromString("10.00"),
+			},
+		},
+	}
+	cfg := temporal.ProcessingConfig{
+		TaxRate:     decimal.RequireFromString("0.10"),
+		ShippingFee: decimal.RequireFromString("4.99"),
+	}
+	total := mock.MatchedBy(func(amount decimal.Decimal) bool {
+		return amount.Equal(decimal.RequireFromString("26.99"))
+	})
+
+	tests := []struct {
+		name          string
+		authorization temporal.PaymentAuthorization
+		gatewayErr    error
+		err           string
+	}{
+		{
+			name:          "Approved",
+			authorization: temporal.PaymentAuthorization{ID: "auth-1", Approved: true, Amount: decimal.RequireFromString("26.99")},
+		},
+		{
+			name:          "Declined",
+			authorization: temporal.PaymentAuthorization{Approved: false, DeclineReason: "insufficient funds"},
+			err:           "payment declined",
+		},
+		{
+			name:       "Gateway error",
+			gatewayErr: errors.New("test error"),
+			err:        "failed to authorize payment for order " + dummyOrderID,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			paymentGateway := temporalmocks.NewMockPaymentGateway(s.T())
+			paymentGateway.EXPECT().
+				AuthorizePayment(mock.Anything, order.ID, total).
+				Return(tt.authorization, tt.gatewayErr)
+
+			activities := temporal.NewOrderActivities(nil,
+				temporal.WithPaymentGateway(paymentGateway),
+				temporal.WithProcessingConfig(cfg),
+			)
+			s.env.RegisterActivity(activities.AuthorizePayment)
+
+			// Invoke
+			pricing := pricedAsGiven(order)
+			val, err := s.env.ExecuteActivity(activities.AuthorizePayment, order, pricing, taxedAt(pricing, cfg.TaxRate))
+
+			// Assert
+			if tt.err != "" {
+				s.Require().ErrorContains(err, tt.err)
+				return
+			}
+			s.Require().NoError(err)
+			var got temporal.PaymentAuthorization
+			s.Require().NoError(val.Get(&got))
+			s.Equal("auth-1", got.ID)
+			s.True(got.Approved)
+		})
+	}
+}
+
+func (s *ActivityTestSuite) TestCapturePayment_Error() {
+	// Setup
+	amount := decimal.RequireFromString("26.99")
+	paymentGateway := temporalmocks.NewMockPaymentGateway(s.T())
+	paymentGateway.EXPECT().
+		CapturePayment(mock.Anything, uuid.MustParse(dummyOrderID), mock.Anything).
+		Return(errors.New("test error"))
+
+	activities := temporal.NewOrderActivities(nil, temporal.WithPaymentGateway(paymentGateway))
+	s.env.RegisterActivity(activities.CapturePayment)
+
+	// Invoke
+	_, err := s.env.ExecuteActivity(activities.CapturePayment, uuid.MustParse(dummyOrderID), amount)
+
+	// Assert
+	s.Require().ErrorContains(err, "failed to capture payment for order "+dummyOrderID)
+}
+
+func (s *ActivityTestSuite) TestPaymentErrorTypes() {
+	orderID := uuid.MustParse(dummyOrderID)
+	amount := decimal.RequireFromString("26.99")
+
+	tests := []struct {
+		name         string
+		err          error
+		errType      string
+		nonRetryable bool
+	}{
+		{
+			name:         "Declined",
+			err:          &payment.StatusError{StatusCode: 402, Err: payment.ErrDeclined},
+			errType:      temporal.PaymentDeclinedErrorType,
+			nonRetryable: true,
+		},
+		{
+			name:         "Authorization expired",
+			err:          &payment.StatusError{StatusCode: 404, Err: payment.ErrNotFound},
+			errType:      temporal.PaymentNotFoundErrorType,
+			nonRetryable: true,
+		},
+		{
+			name:         "Authorization voided",
+			err:          &payment.StatusError{StatusCode: 409, Message: "authorization was voided", Err: payment.ErrConflict},
+			errType:      temporal.PaymentConflictErrorType,
+			nonRetryable: true,
+		},
+		{
+			name:    "Gateway unavailable",
+			err:     &payment.StatusError{StatusCode: 503, Err: payment.ErrUnavailable},
+			errType: temporal.PaymentUnavailableErrorType,
+		},
+		{
+			name:         "Unexpected response",
+			err:          &payment.StatusError{StatusCode: 418, Err: payment.ErrUnexpectedResponse},
+			errType:      temporal.UnexpectedPaymentResponseErrorType,
+			nonRetryable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			paymentGateway := temporalmocks.NewMockPaymentGateway(s.T())
+			paymentGateway.EXPECT().CapturePayment(mock.Anything, orderID, mock.Anything).Return(tt.err)
+			paymentGateway.EXPECT().RefundPayment(mock.Anything, orderID, mock.Anything).Return(tt.err)
+
+			activities := temporal.NewOrderActivities(nil, temporal.WithPaymentGateway(paymentGateway))
+			s.env.RegisterActivity(activities.CapturePayment)
+			s.env.RegisterActivity(activities.RefundPayment)
+
+			// Invoke
+			_, captureErr := s.env.ExecuteActivity(activities.CapturePayment, orderID, amount)
+			_, refundErr := s.env.ExecuteActivity(activities.RefundPayment, orderID, amount)
+
+			// Assert
+			for _, err := range []error{captureErr, refundErr} {
+				var appErr *sdktemporal.ApplicationError
+				s.Require().ErrorAs(err, &appErr)
+				s.Equal(tt.errType, appErr.Type())
+				s.Equal(tt.nonRetryable, appErr.NonRetryable())
+			}
+			s.ErrorContains(captureErr, "failed to capture payment for order "+dummyOrderID)
+			s.ErrorContains(refundErr, "failed to refund payment for order "+dummyOrderID)
+		})
+	}
+}
+
+func (s *ActivityTestSuite) TestValidate_ReportsAllUnavailableProducts() {
+	productA := uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721")
+	productB := uuid.MustParse("5a1c1f6e-0c43-4c1c-9a55-1b7f0f5c2c1e")
+	productC := uuid.MustParse("0f4b6a43-1f0e-4c4e-a1f3-3e2e9f4b7c10")
+	order := temporal.Order{
+		ID:       uuid.MustParse(dummyOrderID),
+		Currency: "AUD",
+		LineItems: []temporal.LineItem{
+			{ProductID: productA, Quantity: 1, PricePerItem: decimal.RequireFromString("1.00")},
+			{ProductID: productB, Quantity: 2, PricePerItem: decimal.RequireFromString("1.00")},
+			{ProductID: productC, Quantity: 3, PricePerItem: decimal.RequireFromString("1.00")},
+		},
+	}
+	quantities := map[uuid.UUID]int32{productA: 1, productB: 2, productC: 3}
+	availability := map[uuid.UUID]bool{productA: true, productB: false, productC: false}
+
+	tests := []struct {
+		name       string
+		setupMocks func(mockIC *temporalmocks.MockInventoryChecker) temporal.ActivityOption
+	}{
+		{
+			name: "Batch checker",
+			setupMocks: func(mockIC *temporalmocks.MockInventoryChecker) temporal.ActivityOption {
+				batchChecker := temporalmocks.NewMockBatchInventoryChecker(s.T())
+				batchChecker.EXPECT().CheckInventoryBatch(mock.Anything, quantities).Return(availability, nil).Once()
+				return temporal.WithBatchInventoryChecker(batchChecker)
+			},
+		},
+		{
+			name: "One check per product",
+			setupMocks: func(mockIC *temporalmocks.MockInventoryChecker) temporal.ActivityOption {
+				for productID, quantity := range quantities {
+					mockIC.EXPECT().CheckInventory(mock.Anything, productID, quantity).Return(availability[productID], nil).Once()
+				}
+				return func(*temporal.OrderActivities) {}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			inventoryChecker := temporalmocks.NewMockInventoryChecker(s.T())
+			activities := temporal.NewOrderActivities(inventoryChecker, tt.setupMocks(inventoryChecker))
+			s.env.RegisterActivity(activities.Validate)
+
+			// Invoke
+			_, err := s.env.ExecuteActivity(activities.Validate, order)
+
+			// Assert
+			s.Require().ErrorContains(err, "insufficient inventory for products")
+			s.ErrorContains(err, productB.String())
+			s.ErrorContains(err, productC.String())
+			s.NotContains(err.Error(), productA.String())
+
+			var appErr *sdktemporal.ApplicationError
+			s.Require().ErrorAs(err, &appErr)
+			var unavailable []uuid.UUID
+			s.Require().NoError(appErr.Details(&unavailable))
+			s.Equal([]uuid.UUID{productC, productB}, unavailable, "unavailable products should be sorted")
+		})
+	}
+}
+
+func (s *ActivityTestSuite) TestValidate_InventoryErrorTypes() {
+	order := temporal.Order{
+		ID:       uuid.MustParse(dummyOrderID),
+		Currency: "AUD",
+		LineItems: []temporal.LineItem{
+			{
+				ProductID:    uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721"),
+				Quantity:     1,
+				PricePerItem: decimal.RequireFromString("123.45"),
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		err            error
+		errType        string
+		nonRetryable   bool
+		nextRetryDelay time.Duration
+	}{
+		{
+			name:         "Invalid product",
+			err:          &inventory.StatusError{StatusCode: 400, Message: "unknown product", Err: inventory.ErrInvalidProduct},
+			errType:      temporal.InvalidProductErrorType,
+			nonRetryable: true,
+		},
+		{
+			name:    "Service unavailable",
+			err:     &inventory.StatusError{StatusCode: 503, Err: inventory.ErrUnavailable},
+			errType: temporal.InventoryUnavailableErrorType,
+		},
+		{
+			name:    "Rate limited",
+			err:     &inventory.StatusError{StatusCode: 429, Err: inventory.ErrRateLimited},
+			errType: temporal.InventoryRateLimitedErrorType,
+		},
+		{
+			name:           "Rate limited with Retry-After",
+			err:            &inventory.StatusError{StatusCode: 429, Err: inventory.ErrRateLimited, RetryAfter: 5 * time.Second},
+			errType:        temporal.InventoryRateLimitedErrorType,
+			nextRetryDelay: 5 * time.Second,
+		},
+		{
+			name:           "Service unavailable with Retry-After",
+			err:            &inventory.StatusError{StatusCode: 503, Err: inventory.ErrUnavailable, RetryAfter: time.Minute},
+			errType:        temporal.InventoryUnavailableErrorType,
+			nextRetryDelay: time.Minute,
+		},
+		{
+			name:         "Unauthorized",
+			err:          &inventory.StatusError{StatusCode: 401, Err: inventory.ErrUnauthorized},
+			errType:      temporal.InventoryUnauthorizedErrorType,
+			nonRetryable: true,
+		},
+		{
+			name:         "Unexpected status",
+			err:          &inventory.StatusError{StatusCode: 418, Err: inventory.ErrUnexpectedStatus},
+			errType:      temporal.UnexpectedInventoryResponseErrorType,
+			nonRetryable: true,
+		},
+		{
+			name:           "Circuit breaker open",
+			err:            &inventory.CircuitOpenError{RetryAfter: 20 * time.Second},
+			errType:        temporal.InventoryCircuitOpenErrorType,
+			nextRetryDelay: 20 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			inventoryChecker := temporalmocks.NewMockInventoryChecker(s.T())
+			inventoryChecker.EXPECT().CheckInventory(mock.Anything, mock.Anything, mock.Anything).Return(false, tt.err)
+
+			activities := temporal.NewOrderActivities(inventoryChecker)
+			s.env.RegisterActivity(activities.Validate)
+
+			// Invoke
+			_, err := s.env.ExecuteActivity(activities.Validate, order)
+
+			// Assert
+			var appErr *sdktemporal.ApplicationError
+			s.Require().ErrorAs(err, &appErr)
+			s.Equal(tt.errType, appErr.Type())
+			s.Equal(tt.nonRetryable, appErr.NonRetryable())
+			s.Equal(tt.nextRetryDelay, appErr.NextRetryDelay())
+			s.ErrorContains(err, "failed to check inventory for product")
+		})
+	}
+}
+
+// warehouseStock returns an inventory checker for a warehouse that has the
+// given products in stock.
+func (s *ActivityTestSuite) warehouseStock(products ...uuid.UUID) *temporalmocks.MockInventoryChecker {
+	checker := temporalmocks.NewMockInventoryChecker(s.T())
+	checker.EXPECT().CheckInventory(mock.Anything, mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, productID uuid.UUID, _ int32) (bool, error) {
+			return slices.Contains(products, productID), nil
+		}).
+		Maybe()
+	return checker
+}
+
+func (s *ActivityTestSuite) TestValidate_RoutesToWarehouses() {
+	productA := uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721")
+	productB := uuid.MustParse("5a1c1f6e-0c43-4c1c-9a55-1b7f0f5c2c1e")
+	order := temporal.Order{
+		ID:       uuid.MustParse(dummyOrderID),
+		Currency: "AUD",
+		LineItems: []temporal.LineItem{
+			{ProductID: productA, Quantity: 1, PricePerItem: decimal.RequireFromString("1.00")},
+			{ProductID: productB, Quantity: 2, PricePerItem: decimal.RequireFromString("1.00")},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		strategy temporal.RoutingStrategy
+		address  *temporal.Address
+		want     []string
+	}{
+		{
+			name:     "Priority",
+			strategy: temporal.RouteByPriority,
+			want:     []string{"sydney", "perth"},
+		},
+		{
+			name:     "Fewest splits",
+			strategy: temporal.RouteFewestSplits,
+			want:     []string{"perth", "perth"},
+		},
+		{
+			name:     "Nearest in the same region",
+			strategy: temporal.RouteToNearest,
+			address:  &temporal.Address{Country: "AU", Region: "wa"},
+			want:     []string{"perth", "perth"},
+		},
+		{
+			name:     "Nearest in the same country",
+			strategy: temporal.RouteToNearest,
+			address:  &temporal.Address{Country: "AU", Region: "VIC"},
+			want:     []string{"sydney", "perth"},
+		},
+		{
+			name:     "Nearest abroad",
+			strategy: temporal.RouteToNearest,
+			address:  &temporal.Address{Country: "NZ"},
+			want:     []string{"auckland", "auckland"},
+		},
+		{
+			name:     "Nearest without a shipping address",
+			strategy: temporal.RouteToNearest,
+			want:     []string{"sydney", "perth"},
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			activities := temporal.NewOrderActivities(nil, temporal.WithWarehouses(tt.strategy,
+				temporal.Warehouse{Name: "auckland", Priority: 3, Country: "NZ", Inventory: s.warehouseStock(productA, productB)},
+				temporal.Warehouse{Name: "perth", Priority: 2, Country: "AU", Region: "WA", Inventory: s.warehouseStock(productA, productB)},
+				temporal.Warehouse{Name: "sydney", Priority: 1, Country: "AU", Region: "NSW", Inventory: s.warehouseStock(productA)},
+			))
+			s.env.RegisterActivity(activities.Validate)
+			order := order
+			order.ShippingAddress = tt.address
+
+			// Invoke
+			val, err := s.env.ExecuteActivity(activities.Validate, order)
+
+			// Assert
+			s.Require().NoError(err)
+			var allocations []temporal.Allocation
+			s.Require().NoError(val.Get(&allocations))
+			s.Equal([]temporal.Allocation{
+				{ProductID: productA, Quantity: 1, Warehouse: tt.want[0]},
+				{ProductID: productB, Quantity: 2, Warehouse: tt.want[1]},
+			}, allocations)
+		})
+	}
+}
+
+func (s *ActivityTestSuite) TestValidate_NoWarehouseHasStock() {
+	// Setup
+	productA := uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721")
+	productB := uuid.MustParse("5a1c1f6e-0c43-4c1c-9a55-1b7f0f5c2c1e")
+	activities := temporal.NewOrderActivities(nil, temporal.WithWarehouses(temporal.RouteByPriority,
+		temporal.Warehouse{Name: "perth", Inventory: s.warehouseStock(productA)},
+		temporal.Warehouse{Name: "sydney", Inventory: s.warehouseStock()},
+	))
+	s.env.RegisterActivity(activities.Validate)
+
+	// Invoke
+	_, err := s.env.ExecuteActivity(activities.Validate, temporal.Order{
+		ID:       uuid.MustParse(dummyOrderID),
+		Currency: "AUD",
+		LineItems: []temporal.LineItem{
+			{ProductID: productA, Quantity: 1, PricePerItem: decimal.RequireFromString("1.00")},
+			{ProductID: productB, Quantity: 1, PricePerItem: decimal.RequireFromString("1.00")},
+		},
+	})
+
+	// Assert
+	var appErr *sdktemporal.ApplicationError
+	s.Require().ErrorAs(err, &appErr)
+	s.Equal(temporal.InsufficientInventoryErrorType, appErr.Type())
+	var unavailable []uuid.UUID
+	s.Require().NoError(appErr.Details(&unavailable))
+	s.Equal([]uuid.UUID{productB}, unavailable)
+}
+
+func (s *ActivityTestSuite) TestReserveInventory_AcrossWarehouses() {
+	// Setup
+	productA := uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721")
+	productB := uuid.MustParse("5a1c1f6e-0c43-4c1c-9a55-1b7f0f5c2c1e")
+	orderID := uuid.MustParse(dummyOrderID)
+	order := temporal.Order{
+		ID: orderID,
+		LineItems: []temporal.LineItem{
+			{ProductID: productA, Quantity: 1, PricePerItem: decimal.RequireFromString("1.00")},
+			{ProductID: productB, Quantity: 2, PricePerItem: decimal.RequireFromString("1.00")},
+			{ProductID: productA, Quantity: 3, PricePerItem: decimal.RequireFromString("1.00")},
+		},
+	}
+	allocations := []temporal.Allocation{
+		{ProductID: productA, Quantity: 1, Warehouse: "sydney"},
+		{ProductID: productB, Quantity: 2, Warehouse: "perth"},
+		{ProductID: productA, Quantity: 3, Warehouse: "sydney"},
+	}
+
+	perth := temporalmocks.NewMockInventoryReserver(s.T())
+	perth.EXPECT().ReserveInventory(mock.Anything, orderID, map[uuid.UUID]int32{productB: 2}, time.Hour).Return(true, nil).Once()
+	perth.EXPECT().CommitReservation(mock.Anything, orderID).Return(nil).Once()
+	sydney := temporalmocks.NewMockInventoryReserver(s.T())
+	sydney.EXPECT().ReserveInventory(mock.Anything, orderID, map[uuid.UUID]int32{productA: 4}, time.Hour).Return(true, nil).Once()
+	sydney.EXPECT().CommitReservation(mock.Anything, orderID).Return(nil).Once()
+	sydney.EXPECT().RestockInventory(mock.Anything, orderID, map[uuid.UUID]int32{productA: 1}, dummyOrderID+"-restock-sydney").Return(nil).Once()
+
+	activities := temporal.NewOrderActivities(nil, temporal.WithWarehouses(temporal.RouteByPriority,
+		temporal.Warehouse{Name: "perth", Reserver: perth},
+		temporal.Warehouse{Name: "sydney", Reserver: sydney},
+	))
+	s.env.RegisterActivity(activities.ReserveInventory)
+	s.env.RegisterActivity(activities.CommitInventory)
+	s.env.RegisterActivity(activities.RestockInventory)
+
+	// Invoke
+	_, reserveErr := s.env.ExecuteActivity(activities.ReserveInventory, order, allocations, time.Hour)
+	_, commitErr := s.env.ExecuteActivity(activities.CommitInventory, orderID, allocations)
+	_, restockErr := s.env.ExecuteActivity(activities.RestockInventory, orderID, []temporal.LineItem{
+		{ProductID: productA, Quantity: 1, PricePerItem: decimal.RequireFromString("1.00")},
+	}, allocations)
+
+	// Assert
+	s.Require().NoError(reserveErr)
+	s.Require().NoError(commitErr)
+	s.Require().NoError(restockErr, "only the warehouse the returned item came from should be restocked")
+}
+
+func (s *ActivityTestSuite) TestRestockInventory_RetriedWithSameKeys() {
+	// Setup
+	productA, productB := uuid.New(), uuid.New()
+	orderID := uuid.MustParse(dummyOrderID)
+	items := []temporal.LineItem{
+		{ProductID: productA, Quantity: 1, PricePerItem: decimal.RequireFromString("1.00")},
+		{ProductID: productB, Quantity: 2, PricePerItem: decimal.RequireFromString("1.00")},
+	}
+	allocations := []temporal.Allocation{
+		{ProductID: productA, Quantity: 1, Warehouse: "perth"},
+		{ProductID: productB, Quantity: 2, Warehouse: "sydney"},
+	}
+
+	// Perth is restocked on both attempts, as the activity does not know
+	// the first succeeded, with the same key so that it is applied once.
+	perth := temporalmocks.NewMockInventoryReserver(s.T())
+	perth.EXPECT().RestockInventory(mock.Anything, orderID, map[uuid.UUID]int32{productA: 1}, dummyOrderID+"-restock-perth").Return(nil).Twice()
+	sydney := temporalmocks.NewMockInventoryReserver(s.T())
+	sydney.EXPECT().RestockInventory(mock.Anything, orderID, map[uuid.UUID]int32{productB: 2}, dummyOrderID+"-restock-sydney").Return(inventory.ErrUnavailable).Once()
+	sydney.EXPECT().RestockInventory(mock.Anything, orderID, map[uuid.UUID]int32{productB: 2}, dummyOrderID+"-restock-sydney").Return(nil).Once()
+
+	activities := temporal.NewOrderActivities(nil, temporal.WithWarehouses(temporal.RouteByPriority,
+		temporal.Warehouse{Name: "perth", Reserver: perth},
+		temporal.Warehouse{Name: "sydney", Reserver: sydney},
+	))
+	s.env.RegisterActivity(activities.RestockInventory)
+
+	// Invoke
+	_, firstErr := s.env.ExecuteActivity(activities.RestockInventory, orderID, items, allocations)
+	_, retryErr := s.env.ExecuteActivity(activities.RestockInventory, orderID, items, allocations)
+
+	// Assert
+	s.Require().Error(firstErr)
+	s.Require().NoError(retryErr)
+}
+
+func (s *ActivityTestSuite) TestReleaseInventory_UnknownWarehouse() {
+	// Setup
+	activities := temporal.NewOrderActivities(nil, temporal.WithWarehouses(temporal.RouteByPriority,
+		temporal.Warehouse{Name: "sydney"},
+	))
+	s.env.RegisterActivity(activities.ReleaseInventory)
+
+	// Invoke
+	_, err := s.env.ExecuteActivity(activities.ReleaseInventory, uuid.MustParse(dummyOrderID), []temporal.Allocation{
+		{ProductID: uuid.New(), Quantity: 1, Warehouse: "perth"},
+	})
+
+	// Assert
+	var appErr *sdktemporal.ApplicationError
+	s.Require().ErrorAs(err, &appErr)
+	s.Equal(temporal.UnknownWarehouseErrorType, appErr.Type())
+	s.True(appErr.NonRetryable())
+}
+
+func (s *ActivityTestSuite) TestValidate_OrderConsistency() {
+	productID := uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721")
+	processingConfig := temporal.ProcessingConfig{
+		TaxRate:     decimal.RequireFromString("0.10"),
+		ShippingFee: decimal.RequireFromString("4.99"),
+	}
+	validOrder := func() temporal.Order {
+		return temporal.Order{
+			ID:         uuid.MustParse(dummyOrderID),
+			CustomerID: "customer-1",
+			Currency:   "AUD",
+			LineItems: []temporal.LineItem{
+				{ProductID: productID, Quantity: 2, PricePerItem: decimal.RequireFromString("10.00"), Currency: "AUD"},
+			},
+			ShippingAddress: &temporal.Address{Line1: "1 George St", City: "Sydney", Region: "NSW", PostalCode: "2000", Country: "AU"},
+			BillingAddress:  &temporal.Address{Country: "NZ"},
+			Totals: &temporal.OrderTotals{
+				Currency: "AUD",
+				Subtotal: decimal.RequireFromString("20.00"),
+				Tax:      decimal.RequireFromString("2.00"),
+				Shipping: decimal.RequireFromString("4.99"),
+				Total:    decimal.RequireFromString("26.99"),
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(order *temporal.Order)
+		err    string
+	}{
+		{
+			name:   "Consistent order",
+			modify: func(order *temporal.Order) {},
+		},
+		{
+			name:   "Without totals or addresses",
+			modify: func(order *temporal.Order) { order.Totals, order.ShippingAddress, order.BillingAddress = nil, nil, nil },
+		},
+		{
+			name:   "Missing currency",
+			modify: func(order *temporal.Order) { order.Currency = "" },
+			err:    "currency is required",
+		},
+		{
+			name:   "Unknown currency",
+			modify: func(order *temporal.Order) { order.Currency = "XYZ" },
+			err:    "currency must be an ISO 4217 currency code",
+		},
+		{
+			name:   "Mixed currencies",
+			modify: func(order *temporal.Order) { order.LineItems[0].Currency = "USD" },
+			err:    "line_items[0].currency must match the order currency AUD",
+		},
+		{
+			name:   "Zero quantity",
+			modify: func(order *temporal.Order) { order.LineItems[0].Quantity = 0 },
+			err:    "line_items[0].quantity must be greater than 0",
+		},
+		{
+			name:   "Negative price",
+			modify: func(order *temporal.Order) { order.LineItems[0].PricePerItem = decimal.RequireFromString("-1.00") },
+			err:    "line_items[0].price_per_item must be positive",
+		},
+		{
+			name:   "Invalid country",
+			modify: func(order *temporal.Order) { order.BillingAddress.Country = "Australia" },
+			err:    "billing_address.country must be an ISO 3166-1 alpha-2 country code",
+		},
+		{
+			name:   "Totals in another currency",
+			modify: func(order *temporal.Order) { order.Totals.Currency = "USD" },
+			err:    "totals.currency must match the order currency AUD",
+		},
+		{
+			name: "Totals that do not add up",
+			modify: func(order *temporal.Order) {
+				order.Totals.Discount = decimal.RequireFromString("5.00")
+			},
+			err: "totals.total must be the subtotal less the discount plus tax and shipping, 21.99",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			order := validOrder()
+			tt.modify(&order)
+
+			inventoryChecker := temporalmocks.NewMockInventoryChecker(s.T())
+			if tt.err == "" {
+				inventoryChecker.EXPECT().CheckInventory(mock.Anything, productID, int32(2)).Return(true, nil)
+			}
+
+			activities := temporal.NewOrderActivities(inventoryChecker, temporal.WithProcessingConfig(processingConfig))
+			s.env.RegisterActivity(activities.Validate)
+
+			// Invoke
+			_, err := s.env.ExecuteActivity(activities.Validate, order)
+
+			// Assert
+			if tt.err == "" {
+				s.Require().NoError(err)
+				return
+			}
+			s.Require().ErrorContains(err, tt.err)
+
+			var appErr *sdktemporal.ApplicationError
+			s.Require().ErrorAs(err, &appErr)
+			s.Equal(temporal.InvalidOrderErrorType, appErr.Type())
+			s.True(appErr.NonRetryable(), "an invalid order should not be retried")
+		})
+	}
+}
+
+func (s *ActivityTestSuite) TestValidate_ReportsAllViolations() {
+	// Setup
+	productID := uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721")
+	lineItems := []temporal.LineItem{
+		{ProductID: productID, Quantity: 0, PricePerItem: decimal.RequireFromString("10.00")},
+		{ProductID: uuid.Nil, Quantity: 1, PricePerItem: decimal.Zero, Currency: "USD"},
+		{ProductID: productID, Quantity: 1, PricePerItem: decimal.RequireFromString("10.00")},
+	}
+	for range temporal.MaxLineItems {
+		lineItems = append(lineItems, temporal.LineItem{ProductID: uuid.New(), Quantity: 1, PricePerItem: decimal.RequireFromString("1.00")})
+	}
+	order := temporal.Order{
+		ID:              uuid.MustParse(dummyOrderID),
+		Currency:        "AUD",
+		LineItems:       lineItems,
+		ShippingAddress: &temporal.Address{City: "Sydney"},
+	}
+
+	activities := temporal.NewOrderActivities(temporalmocks.NewMockInventoryChecker(s.T()))
+	s.env.RegisterActivity(activities.Validate)
+
+	// Invoke
+	_, err := s.env.ExecuteActivity(activities.Validate, order)
+
+	// Assert
+	var appErr *sdktemporal.ApplicationError
+	s.Require().ErrorAs(err, &appErr)
+	s.Equal(temporal.InvalidOrderErrorType, appErr.Type())
+	s.True(appErr.NonRetryable(), "an invalid order should not be retried")
+
+	var violations []temporal.FieldViolation
+	s.Require().NoError(appErr.Details(&violations))
+	s.ElementsMatch([]temporal.FieldViolation{
+		{Field: "line_items", Rule: "max_line_items", Param: "100", Message: "must have at most 100 items"},
+		{Field: "line_items[2].product_id", Rule: "unique_products", Message: "must not repeat a product of an earlier line item"},
+		{Field: "line_items[0].quantity", Rule: "gt", Param: "0", Message: "must be greater than 0"},
+		{Field: "line_items[1].product_id", Rule: "nonnil_uuid", Message: "must be a non-nil UUID"},
+		{Field: "line_items[1].price_per_item", Rule: "positive_decimal", Message: "must be positive"},
+		{Field: "line_items[1].currency", Rule: "order_currency", Param: "AUD", Message: "must match the order currency AUD"},
+		{Field: "shipping_address.country", Rule: "required", Message: "is required"},
+	}, violations)
+}
+
+func (s *ActivityTestSuite) TestPriceOrder() {
+	productID := uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721")
+	order := temporal.Order{
+		ID:          uuid.MustParse(dummyOrderID),
+		Currency:    "AUD",
+		CouponCodes: []string{"SPRING10"},
+		LineItems: []temporal.LineItem{
+			{ProductID: productID, Quantity: 2, PricePerItem: decimal.RequireFromString("10.00")},
+		},
+	}
+	discounted := temporal.PriceBreakdown{
+		Lines: []temporal.LinePrice{
+			{ProductID: productID, Quantity: 2, UnitPrice: decimal.RequireFromString("12.00"), Total: decimal.RequireFromString("24.00")},
+		},
+		Discounts: []temporal.Discount{{Promotion: "SPRING10", Amount: decimal.RequireFromString("2.40")}},
+		Coupons:   []string{"SPRING10"},
+	}
+
+	tests := []struct {
+		name      string
+		engine    func() temporal.PricingEngine
+		wantTotal string
+		errType   string
+		retryable bool
+	}{
+		{
+			name:      "Prices as given without an engine",
+			wantTotal: "20",
+		},
+		{
+			name: "Priced by the engine",
+			engine: func() temporal.PricingEngine {
+				engine := temporalmocks.NewMockPricingEngine(s.T())
+				engine.EXPECT().PriceOrder(mock.Anything, mock.Anything).Return(discounted, nil)
+				return engine
+			},
+			wantTotal: "24",
+		},
+		{
+			name: "Unknown coupon",
+			engine: func() temporal.PricingEngine {
+				engine := temporalmocks.NewMockPricingEngine(s.T())
+				engine.EXPECT().PriceOrder(mock.Anything, mock.Anything).Return(temporal.PriceBreakdown{}, fmt.Errorf("coupon SPRING10: %w", temporal.ErrUnknownCoupon))
+				return engine
+			},
+			errType: temporal.UnknownCouponErrorType,
+		},
+		{
+			name: "Engine error",
+			engine: func() temporal.PricingEngine {
+				engine := temporalmocks.NewMockPricingEngine(s.T())
+				engine.EXPECT().PriceOrder(mock.Anything, mock.Anything).Return(temporal.PriceBreakdown{}, errors.New("test error"))
+				return engine
+			},
+			retryable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			var opts []temporal.ActivityOption
+			if tt.engine != nil {
+				opts = append(opts, temporal.WithPricingEngine(tt.engine()))
+			}
+			activities := temporal.NewOrderActivities(nil, opts...)
+			s.env.RegisterActivity(activities.PriceOrder)
+
+			// Invoke
+			val, err := s.env.ExecuteActivity(activities.PriceOrder, order)
+
+			// Assert
+			if tt.retryable {
+				s.Require().ErrorContains(err, "failed to price order "+dummyOrderID)
+				var appErr *sdktemporal.ApplicationError
+				s.Require().ErrorAs(err, &appErr)
+				s.False(appErr.NonRetryable())
+				return
+			}
+			if tt.errType != "" {
+				var appErr *sdktemporal.ApplicationError
+				s.Require().ErrorAs(err, &appErr)
+				s.Equal(tt.errType, appErr.Type())
+				s.True(appErr.NonRetryable())
+				return
+			}
+			s.Require().NoError(err)
+			var got temporal.PriceBreakdown
+			s.Require().NoError(val.Get(&got))
+			s.Require().Len(got.Lines, 1)
+			s.Equal(tt.wantTotal, got.Lines[0].Total.String())
+		})
+	}
+}
+
+func (s *ActivityTestSuite) TestCalculateTax() {
+	groceries := uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721")
+	shirt := uuid.MustParse("5a1c1f6e-0c43-4c1c-9a55-1b7f0f5c2c1e")
+	order := temporal.Order{
+		ID:       uuid.MustParse(dummyOrderID),
+		Currency: "AUD",
+		LineItems: []temporal.LineItem{
+			{ProductID: groceries, Quantity: 1, PricePerItem: decimal.RequireFromString("30.00"), TaxCategory: "food"},
+			{ProductID: shirt, Quantity: 1, PricePerItem: decimal.RequireFromString("10.00")},
+		},
+		ShippingAddress: &temporal.Address{City: "Sydney", Region: "NSW", Country: "AU"},
+		BillingAddress:  &temporal.Address{Country: "NZ"},
+	}
+	// A 4.00 order discount leaves 27.00 of groceries and 9.00 of the shirt
+	// to tax.
+	pricing := pricedAsGiven(order)
+	pricing.Discounts = []temporal.Discount{{Promotion: "SPRING10", Amount: decimal.RequireFromString("4.00")}}
+	processingConfig := temporal.ProcessingConfig{
+		TaxRate:     decimal.RequireFromString("0.10"),
+		ShippingFee: decimal.RequireFromString("4.99"),
+	}
+	// Food is untaxed and the shirt is taxed at 10%.
+	jurisdictionTax := temporal.TaxBreakdown{
+		Jurisdiction: "AU-NSW",
+		Lines: []temporal.LineTax{
+			{ProductID: groceries, TaxCategory: "food", Taxable: decimal.RequireFromString("27.00"), Rate: decimal.Zero, Amount: decimal.Zero},
+			{ProductID: shirt, Taxable: decimal.RequireFromString("9.00"), Rate: decimal.RequireFromString("0.10"), Amount: decimal.RequireFromString("0.9")},
+		},
+		Total: decimal.RequireFromString("123"),
+	}
+	taxRequest := mock.MatchedBy(func(req temporal.TaxRequest) bool {
+		return req.OrderID == order.ID &&
+			req.Address.Country == "AU" &&
+			len(req.Lines) == 2 &&
+			req.Lines[0].TaxCategory == "food" &&
+			req.Lines[0].Amount.Equal(decimal.RequireFromString("27.00")) &&
+			req.Lines[1].Amount.Equal(decimal.RequireFromString("9.00"))
+	})
+	totals := func(tax, total string) *temporal.OrderTotals {
+		return &temporal.OrderTotals{
+			Currency: "AUD",
+			Subtotal: decimal.RequireFromString("40.00"),
+			Discount: decimal.RequireFromString("4.00"),
+			Tax:      decimal.RequireFromString(tax),
+			Shipping: decimal.RequireFromString("4.99"),
+			Total:    decimal.RequireFromString(total),
+		}
+	}
+
+	tests := []struct {
+		name             string
+		calculator       func() temporal.TaxCalculator
+		totals           *temporal.OrderTotals
+		wantJurisdiction string
+		wantTax          string
+		errType          string
+		retryable        bool
+	}{
+		{
+			name:    "Flat rate without a calculator",
+			totals:  totals("3.60", "44.59"),
+			wantTax: "3.6",
+		},
+		{
+			name: "Taxed by the calculator",
+			calculator: func() temporal.TaxCalculator {
+				calculator := temporalmocks.NewMockTaxCalculator(s.T())
+				calculator.EXPECT().CalculateTax(mock.Anything, taxRequest).Return(jurisdictionTax, nil)
+				return calculator
+			},
+			totals:           totals("0.90", "41.89"),
+			wantJurisdiction: "AU-NSW",
+			wantTax:          "0.9",
+		},
+		{
+			name: "Totals shown at the flat rate",
+			calculator: func() temporal.TaxCalculator {
+				calculator := temporalmocks.NewMockTaxCalculator(s.T())
+				calculator.EXPECT().CalculateTax(mock.Anything, mock.Anything).Return(jurisdictionTax, nil)
+				return calculator
+			},
+			totals:  totals("3.60", "44.59"),
+			errType: temporal.InvalidOrderErrorType,
+		},
+		{
+			name: "Unsupported jurisdiction",
+			calculator: func() temporal.TaxCalculator {
+				calculator := temporalmocks.NewMockTaxCalculator(s.T())
+				calculator.EXPECT().CalculateTax(mock.Anything, mock.Anything).Return(temporal.TaxBreakdown{}, fmt.Errorf("AU-NSW: %w", tax.ErrUnsupportedJurisdiction))
+				return calculator
+			},
+			errType: temporal.UnsupportedTaxJurisdictionErrorType,
+		},
+		{
+			name: "Invalid request",
+			calculator: func() temporal.TaxCalculator {
+				calculator := temporalmocks.NewMockTaxCalculator(s.T())
+				calculator.EXPECT().CalculateTax(mock.Anything, mock.Anything).Return(temporal.TaxBreakdown{}, &tax.StatusError{StatusCode: 400, Err: tax.ErrInvalidRequest})
+				return calculator
+			},
+			errType: temporal.InvalidTaxRequestErrorType,
+		},
+		{
+			name: "Tax service unavailable",
+			calculator: func() temporal.TaxCalculator {
+				calculator := temporalmocks.NewMockTaxCalculator(s.T())
+				calculator.EXPECT().CalculateTax(mock.Anything, mock.Anything).Return(temporal.TaxBreakdown{}, &tax.StatusError{StatusCode: 503, Err: tax.ErrUnavailable})
+				return calculator
+			},
+			errType:   temporal.TaxUnavailableErrorType,
+			retryable: true,
+		},
+		{
+			name: "Calculator error",
+			calculator: func() temporal.TaxCalculator {
+				calculator := temporalmocks.NewMockTaxCalculator(s.T())
+				calculator.EXPECT().CalculateTax(mock.Anything, mock.Anything).Return(temporal.TaxBreakdown{}, errors.New("test error"))
+				return calculator
+			},
+			retryable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			order := order
+			order.Totals = tt.totals
+
+			opts := []temporal.ActivityOption{temporal.WithProcessingConfig(processingConfig)}
+			if tt.calculator != nil {
+				opts = append(opts, temporal.WithTaxCalculator(tt.calculator()))
+			}
+			activities := temporal.NewOrderActivities(nil, opts...)
+			s.env.RegisterActivity(activities.CalculateTax)
+
+			// Invoke
+			val, err := s.env.ExecuteActivity(activities.CalculateTax, order, pricing)
+
+			// Assert
+			if tt.retryable || tt.errType != "" {
+				var appErr *sdktemporal.ApplicationError
+				s.Require().ErrorAs(err, &appErr)
+				s.Equal(!tt.retryable, appErr.NonRetryable())
+				if tt.errType != "" {
+					s.Equal(tt.errType, appErr.Type())
+				}
+				if tt.errType != temporal.InvalidOrderErrorType {
+					s.ErrorContains(err, "failed to calculate tax for order "+dummyOrderID)
+				}
+				return
+			}
+			s.Require().NoError(err)
+			var got temporal.TaxBreakdown
+			s.Require().NoError(val.Get(&got))
+			s.Require().Len(got.Lines, 2)
+			s.Equal(tt.wantJurisdiction, got.Jurisdiction)
+			s.Equal(tt.wantTax, got.Total.String(), "the total should be the sum of the line taxes")
+		})
+	}
+}
+
+func (s *ActivityTestSuite) TestScreenOrder() {
+	order := temporal.Order{
+		ID:       uuid.MustParse(dummyOrderID),
+		Currency: "AUD",
+		LineItems: []temporal.LineItem{
+			{ProductID: uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721"), Quantity: 2, PricePerItem: decimal.RequireFromString("10.00")},
+		},
+	}
+	processingConfig := temporal.ProcessingConfig{
+		TaxRate:     decimal.RequireFromString("0.10"),
+		ShippingFee: decimal.RequireFromString("4.99"),
+	}
+	pricing := pricedAsGiven(order)
+	tax := taxedAt(pricing, processingConfig.TaxRate)
+	// The order is screened for what it will be charged, including tax and
+	// shipping.
+	screened := mock.MatchedBy(func(req temporal.ScreeningRequest) bool {
+		return req.Order.ID == order.ID && req.Total.Equal(decimal.RequireFromString("26.99"))
+	})
+
+	tests := []struct {
+		name         string
+		scorer       func() temporal.FraudScorer
+		wantDecision temporal.FraudDecision
+		err          string
+	}{
+		{
+			name:         "Accepted without a scorer",
+			wantDecision: temporal.FraudAccept,
+		},
+		{
+			name: "Held for review by the scorer",
+			scorer: func() temporal.FraudScorer {
+				scorer := temporalmocks.NewMockFraudScorer(s.T())
+				scorer.EXPECT().ScoreOrder(mock.Anything, screened).Return(temporal.FraudAssessment{Decision: temporal.FraudReview, Score: 50}, nil)
+				return scorer
+			},
+			wantDecision: temporal.FraudReview,
+		},
+		{
+			name: "Unknown decision",
+			scorer: func() temporal.FraudScorer {
+				scorer := temporalmocks.NewMockFraudScorer(s.T())
+				scorer.EXPECT().ScoreOrder(mock.Anything, mock.Anything).Return(temporal.FraudAssessment{Decision: "maybe"}, nil)
+				return scorer
+			},
+			err: `unknown decision "maybe"`,
+		},
+		{
+			name: "Scorer error",
+			scorer: func() temporal.FraudScorer {
+				scorer := temporalmocks.NewMockFraudScorer(s.T())
+				scorer.EXPECT().ScoreOrder(mock.Anything, mock.Anything).Return(temporal.FraudAssessment{}, errors.New("test error"))
+				return scorer
+			},
+			err: "failed to screen order " + dummyOrderID,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Setup
+			opts := []temporal.ActivityOption{temporal.WithProcessingConfig(processingConfig)}
+			if tt.scorer != nil {
+				opts = append(opts, temporal.WithFraudScorer(tt.scorer()))
+			}
+			activities := temporal.NewOrderActivities(nil, opts...)
+			s.env.RegisterActivity(activities.ScreenOrder)
+
+			// Invoke
+			val, err := s.env.ExecuteActivity(activities.ScreenOrder, order, pricing, tax)
+
+			// Assert
+			if tt.err != "" {
+				s.Require().ErrorContains(err, tt.err)
+				return
+			}
+			s.Require().NoError(err)
+			var got temporal.FraudAssessment
+			s.Require().NoError(val.Get(&got))
+			s.Equal(tt.wantDecision, got.Decision)
+		})
+	}
+}
+
+// pricedAsGiven prices order at the prices of its line items, as PriceOrder
+// does without a pricing engine.
+func pricedAsGiven(order temporal.Order) temporal.PriceBreakdown {
+	pricing := temporal.PriceBreakdown{}
+	for _, item := range order.LineItems {
+		pricing.Lines = append(pricing.Lines, temporal.LinePrice{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.PricePerItem,
+			Total:     item.PricePerItem.Mul(decimal.NewFromInt32(item.Quantity)).Round(2),
+		})
+	}
+	return pricing
+}
+
+// taxedAt taxes every line of pricing at rate, as CalculateTax does without a
+// tax calculator for an order without order discounts.
+func taxedAt(pricing temporal.PriceBreakdown, rate decimal.Decimal) temporal.TaxBreakdown {
+	tax := temporal.TaxBreakdown{Total: decimal.Zero}
+	for _, line := range pricing.Lines {
+		amount := line.Total.Mul(rate).Round(2)
+		tax.Lines = append(tax.Lines, temporal.LineTax{
+			ProductID: line.ProductID,
+			Taxable:   line.Total,
+			Rate:      rate,
+			Amount:    amount,
+		})
+		tax.Total = tax.Total.Add(amount)
+	}
+	return tax
+}
@@ -0,0 +1,261 @@
+package temporal
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// BackorderPolicy decides what happens to an order with line items that are
+// out of stock.
+type BackorderPolicy string
+
+const (
+	// BackorderReject fails the order. This is the default.
+	BackorderReject BackorderPolicy = "reject"
+	// BackorderWait keeps the order BACKORDERED until every line item is in
+	// stock, checking inventory again every poll interval and whenever a
+	// RestockSignal arrives, and fails it if the wait runs out.
+	BackorderWait BackorderPolicy = "wait"
+	// BackorderSplit proceeds with the line items that are in stock and
+	// starts a separate order, with the wait policy, for the rest. An order
+	// with nothing in stock waits as with BackorderWait.
+	BackorderSplit BackorderPolicy = "split"
+)
+
+// RestockSignal tells a backordered order that stock has arrived, so that it
+// checks inventory again without waiting for the next poll.
+const RestockSignal = "restock"
+
+const (
+	// defaultBackorderWait is how long a backordered order waits for stock
+	// when BackorderOptions does not specify it.
+	defaultBackorderWait = 14 * 24 * time.Hour
+	// defaultBackorderPoll is how often a backordered order checks inventory
+	// when BackorderOptions does not specify it.
+	defaultBackorderPoll = time.Hour
+)
+
+// BackorderOptions sets how an order with line items that are out of stock is
+// handled.
+type BackorderOptions struct {
+	Policy BackorderPolicy `json:"policy,omitempty"`
+	// WaitFor is how long a backordered order waits for stock before it
+	// fails. Defaults to defaultBackorderWait.
+	WaitFor time.Duration `json:"wait_for,omitempty"`
+	// PollInterval is how often a backordered order checks inventory again.
+	// Defaults to defaultBackorderPoll.
+	PollInterval time.Duration `json:"poll_interval,omitempty"`
+}
+
+// Backorder is the order started for the line items split off an order that
+// were out of stock.
+type Backorder struct {
+	OrderID    uuid.UUID  `json:"order_id"`
+	WorkflowID string     `json:"workflow_id"`
+	Items      []LineItem `json:"items"`
+}
+
+// validate runs the Validate activity and applies the backorder policy if some
+// line items are out of stock. It returns nil once the order can proceed, and
+// also if the order was cancelled while it was backordered.
+func (w *orderWorkflow) validate(ctx workflow.Context) error {
+	var orderActivities *OrderActivities
+	err := workflow.ExecuteActivity(ctx, orderActivities.Validate, w.params.Order).Get(ctx, &w.allocations)
+	unavailable, ok := insufficientInventory(err)
+	if !ok {
+		return err
+	}
+	return w.applyBackorderPolicy(ctx, unavailable, err)
+}
+
+// applyBackorderPolicy waits for the unavailable products or splits them off
+// as the policy says, or returns cause if the policy rejects the order. An
+// order is only split once; if stock runs short again it waits.
+func (w *orderWorkflow) applyBackorderPolicy(ctx workflow.Context, unavailable []uuid.UUID, cause error) error {
+	var orderActivities *OrderActivities
+	switch w.params.Backorder.Policy {
+	case BackorderSplit:
+		if w.backorder == nil && len(unavailable) < len(quantitiesByProduct(w.params.Order.LineItems)) {
+			if err := w.splitBackorder(ctx, unavailable); err != nil {
+				return err
+			}
+			// The items left may have run out since they were checked, in
+			// which case the order waits for them.
+			err := workflow.ExecuteActivity(ctx, orderActivities.Validate, w.params.Order).Get(ctx, &w.allocations)
+			if _, ok := insufficientInventory(err); ok {
+				return w.awaitStock(ctx, err)
+			}
+			return err
+		}
+		return w.awaitStock(ctx, cause)
+
+	case BackorderWait:
+		return w.awaitStock(ctx, cause)
+
+	default:
+		return cause
+	}
+}
+
+// backordersShortfall reports whether err is ReserveInventory finding that
+// stock ran out after the order was validated, and the backorder policy lets
+// the order wait for it rather than fail.
+func (w *orderWorkflow) backordersShortfall(err error) bool {
+	var appErr *temporal.ApplicationError
+	if !errors.As(err, &appErr) || appErr.Type() != InsufficientInventoryErrorType {
+		return false
+	}
+	return w.params.Backorder.Policy == BackorderWait || w.params.Backorder.Policy == BackorderSplit
+}
+
+// awaitReservableStock applies the backorder policy to an order whose stock
+// ran out between validation and reservation, once its authorization and any
+// partial reservation have been undone. The reservation does not say which
+// products are short, so the order is validated again to find out. If every
+// product still looks in stock, e.g. because other orders hold reservations
+// on it, the order waits for a restock or the next poll all the same.
+func (w *orderWorkflow) awaitReservableStock(ctx workflow.Context, cause error) error {
+	var orderActivities *OrderActivities
+	err := workflow.ExecuteActivity(ctx, orderActivities.Validate, w.params.Order).Get(ctx, &w.allocations)
+	if err == nil {
+		return w.awaitStock(ctx, cause)
+	}
+	unavailable, ok := insufficientInventory(err)
+	if !ok {
+		return err
+	}
+	return w.applyBackorderPolicy(ctx, unavailable, err)
+}
+
+// awaitStock keeps a backordered order waiting until Validate finds every
+// line item in stock. It gives up and returns the last validation error once
+// the order has been backordered for BackorderOptions.WaitFor in all.
+func (w *orderWorkflow) awaitStock(ctx workflow.Context, cause error) error {
+	var orderActivities *OrderActivities
+	w.transition(ctx, Backordered, backorderedTrigger, TransitionRequest{Actor: systemActor, Reason: cause.Error()})
+
+	waitFor := w.params.Backorder.WaitFor
+	if waitFor <= 0 {
+		waitFor = defaultBackorderWait
+	}
+	poll := w.params.Backorder.PollInterval
+	if poll <= 0 {
+		poll = defaultBackorderPoll
+	}
+	// The wait runs from when the order was first backordered, however often
+	// its stock runs short.
+	if w.backorderDeadline.IsZero() {
+		w.backorderDeadline = workflow.Now(ctx).Add(waitFor)
+	}
+	deadline := w.backorderDeadline
+	// The order has just been validated, so an earlier restock is already
+	// accounted for.
+	w.restocked = false
+
+	for {
+		remaining := deadline.Sub(workflow.Now(ctx))
+		if remaining <= 0 {
+			return cause
+		}
+
+		_, err := workflow.AwaitWithTimeout(ctx, min(poll, remaining), func() bool {
+			return w.restocked || w.cancellation != nil
+		})
+		if err != nil {
+			return err
+		}
+		if w.cancellation != nil {
+			return nil
+		}
+		w.restocked = false
+
+		err = workflow.ExecuteActivity(ctx, orderActivities.Validate, w.params.Order).Get(ctx, &w.allocations)
+		if _, ok := insufficientInventory(err); !ok {
+			return err
+		}
+		cause = err
+	}
+}
+
+// receiveRestocks records every RestockSignal for the life of the workflow,
+// however many times the order is backordered.
+func (w *orderWorkflow) receiveRestocks(ctx workflow.Context) {
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		restocks := workflow.GetSignalChannel(ctx, RestockSignal)
+		for restocks.Receive(ctx, nil) {
+			w.restocked = true
+		}
+	})
+}
+
+// splitBackorder removes the unavailable products from the order and starts a
+// backorder for them that outlives this workflow.
+func (w *orderWorkflow) splitBackorder(ctx workflow.Context, unavailable []uuid.UUID) error {
+	var available, backordered []LineItem
+	for _, item := range w.params.Order.LineItems {
+		if slices.Contains(unavailable, item.ProductID) {
+			backordered = append(backordered, item)
+		} else {
+			available = append(available, item)
+		}
+	}
+
+	var backorderID uuid.UUID
+	err := workflow.SideEffect(ctx, func(workflow.Context) any {
+		return uuid.New()
+	}).Get(&backorderID)
+	if err != nil {
+		return fmt.Errorf("failed to generate backorder ID: %w", err)
+	}
+
+	// The backorder is charged for its own items, so the totals of the
+	// original order no longer apply to it.
+	params := w.params
+	params.Order.ID = backorderID
+	params.Order.LineItems = backordered
+	params.Order.Totals = nil
+	params.Backorder.Policy = BackorderWait
+
+	childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID:        "order-" + backorderID.String(),
+		ParentClosePolicy: enumspb.PARENT_CLOSE_POLICY_ABANDON,
+	})
+	var execution workflow.Execution
+	err = workflow.ExecuteChildWorkflow(childCtx, ProccessOrder, params).GetChildWorkflowExecution().Get(ctx, &execution)
+	if err != nil {
+		return fmt.Errorf("failed to start backorder: %w", err)
+	}
+	workflow.GetLogger(ctx).Info("Backordered unavailable items", "backorderID", backorderID, "workflowID", execution.ID)
+
+	w.backorder = &Backorder{
+		OrderID:    backorderID,
+		WorkflowID: execution.ID,
+		Items:      backordered,
+	}
+	w.params.Order.LineItems = available
+	w.params.Order.Totals = nil
+	w.fulfilment = newFulfilment(available)
+	return nil
+}
+
+// insufficientInventory reports whether err is Validate reporting products
+// that are out of stock, and returns them.
+func insufficientInventory(err error) ([]uuid.UUID, bool) {
+	var appErr *temporal.ApplicationError
+	if !errors.As(err, &appErr) || appErr.Type() != InsufficientInventoryErrorType {
+		return nil, false
+	}
+
+	var unavailable []uuid.UUID
+	if err := appErr.Details(&unavailable); err != nil {
+		return nil, false
+	}
+	return unavailable, true
+}
@@ -0,0 +1,94 @@
+package temporal
+
+import (
+	"slices"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+var compensationActivityOptions = workflow.ActivityOptions{
+	StartToCloseTimeout: time.Minute,
+	RetryPolicy: &temporal.RetryPolicy{
+		InitialInterval:    time.Second,
+		BackoffCoefficient: 2,
+		MaximumInterval:    5 * time.Minute,
+		MaximumAttempts:    10,
+	},
+}
+
+// CompensationResult reports the outcome of undoing the steps an order had
+// completed before it failed or was cancelled.
+type CompensationResult struct {
+	Compensated []string              `json:"compensated"`
+	Failed      []CompensationFailure `json:"failed,omitempty"`
+}
+
+// CompensationFailure records a compensation step that could not be completed
+// and needs manual follow-up.
+type CompensationFailure struct {
+	Step  string `json:"step"`
+	Error string `json:"error"`
+}
+
+// Succeeded reports whether every registered compensation step completed.
+func (r CompensationResult) Succeeded() bool {
+	return len(r.Failed) == 0
+}
+
+type compensation struct {
+	step     string
+	activity any
+	args     []any
+}
+
+// compensations is a stack of undo activities registered as each step of the
+// order completes.
+type compensations []compensation
+
+// add registers the activity that undoes a completed step.
+func (c *compensations) add(step string, activity any, args ...any) {
+	*c = append(*c, compensation{
+		step:     step,
+		activity: activity,
+		args:     args,
+	})
+}
+
+// remove drops the compensation registered for step, once the step's effect
+// has been superseded by a later one and no longer needs undoing.
+func (c *compensations) remove(step string) {
+	*c = slices.DeleteFunc(*c, func(comp compensation) bool {
+		return comp.step == step
+	})
+}
+
+// compensate runs the registered activities in reverse order. Every step is
+// attempted even if an earlier one fails, so a single stuck compensation
+// does not leave the remaining side effects in place.
+func (c *compensations) compensate(ctx workflow.Context) CompensationResult {
+	logger := workflow.GetLogger(ctx)
+
+	// Compensations must run even when the workflow itself has been cancelled.
+	ctx, _ = workflow.NewDisconnectedContext(ctx)
+	ctx = workflow.WithActivityOptions(ctx, compensationActivityOptions)
+
+	result := CompensationResult{Compensated: []string{}}
+	for i := len(*c) - 1; i >= 0; i-- {
+		step := (*c)[i]
+		err := workflow.ExecuteActivity(ctx, step.activity, step.args...).Get(ctx, nil)
+		if err != nil {
+			logger.Error("Compensation failed", "step", step.step, "error", err)
+			result.Failed = append(result.Failed, CompensationFailure{
+				Step:  step.step,
+				Error: err.Error(),
+			})
+			continue
+		}
+		result.Compensated = append(result.Compensated, step.step)
+	}
+	*c = nil
+
+	return result
+}
@@ -0,0 +1,149 @@
+package temporal
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/inventory"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/payment"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/tax"
+	"go.temporal.io/sdk/temporal"
+)
+
+// Application error types returned by the inventory activities. Workflows and
+// callers can match on them with temporal.ApplicationError.Type.
+const (
+	InsufficientInventoryErrorType       = "InsufficientInventory"
+	InvalidProductErrorType              = "InvalidProduct"
+	InvalidInventoryRequestErrorType     = "InvalidInventoryRequest"
+	ReservationNotFoundErrorType         = "ReservationNotFound"
+	InventoryUnavailableErrorType        = "InventoryUnavailable"
+	InventoryRateLimitedErrorType        = "InventoryRateLimited"
+	InventoryUnauthorizedErrorType       = "InventoryUnauthorized"
+	UnexpectedInventoryResponseErrorType = "UnexpectedInventoryResponse"
+	InventoryCircuitOpenErrorType        = "InventoryCircuitOpen"
+	UnknownWarehouseErrorType            = "UnknownWarehouse"
+)
+
+var inventoryErrorTypes = []struct {
+	err     error
+	errType string
+}{
+	{inventory.ErrInvalidProduct, InvalidProductErrorType},
+	{inventory.ErrInvalidRequest, InvalidInventoryRequestErrorType},
+	{inventory.ErrReservationNotFound, ReservationNotFoundErrorType},
+	{inventory.ErrUnavailable, InventoryUnavailableErrorType},
+	{inventory.ErrRateLimited, InventoryRateLimitedErrorType},
+	{inventory.ErrUnauthorized, InventoryUnauthorizedErrorType},
+	{inventory.ErrUnexpectedStatus, UnexpectedInventoryResponseErrorType},
+}
+
+// inventoryError wraps an error from the inventory client in an application
+// error whose type identifies the failure. Failures the inventory service
+// will keep returning are marked non-retryable so Temporal does not retry
+// them. A request rejected by the client's circuit breaker is retried once the
+// breaker is due to let requests through again, and one the inventory service
+// asked to be retried later with Retry-After is retried after that delay.
+// Other errors, such as timeouts, are wrapped as they are.
+func inventoryError(message string, err error) error {
+	var circuitErr *inventory.CircuitOpenError
+	if errors.As(err, &circuitErr) {
+		return temporal.NewApplicationErrorWithOptions(message, InventoryCircuitOpenErrorType, temporal.ApplicationErrorOptions{
+			Cause:          err,
+			NextRetryDelay: circuitErr.RetryAfter,
+		})
+	}
+
+	var retryAfter time.Duration
+	var statusErr *inventory.StatusError
+	if errors.As(err, &statusErr) {
+		retryAfter = statusErr.RetryAfter
+	}
+
+	for _, t := range inventoryErrorTypes {
+		if errors.Is(err, t.err) {
+			return temporal.NewApplicationErrorWithOptions(message, t.errType, temporal.ApplicationErrorOptions{
+				NonRetryable:   !inventory.Retryable(err),
+				Cause:          err,
+				NextRetryDelay: retryAfter,
+			})
+		}
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// Application error types returned by the payment activities.
+const (
+	InvalidPaymentRequestErrorType     = "InvalidPaymentRequest"
+	PaymentDeclinedErrorType           = "PaymentDeclined"
+	PaymentNotFoundErrorType           = "PaymentNotFound"
+	PaymentConflictErrorType           = "PaymentConflict"
+	PaymentUnavailableErrorType        = "PaymentUnavailable"
+	UnexpectedPaymentResponseErrorType = "UnexpectedPaymentResponse"
+)
+
+var paymentErrorTypes = []struct {
+	err       error
+	errType   string
+	retryable bool
+}{
+	{payment.ErrInvalidRequest, InvalidPaymentRequestErrorType, false},
+	{payment.ErrDeclined, PaymentDeclinedErrorType, false},
+	{payment.ErrNotFound, PaymentNotFoundErrorType, false},
+	{payment.ErrConflict, PaymentConflictErrorType, false},
+	{payment.ErrUnavailable, PaymentUnavailableErrorType, true},
+	{payment.ErrUnexpectedResponse, UnexpectedPaymentResponseErrorType, false},
+}
+
+// paymentError wraps an error from the PaymentGateway in an application error
+// whose type identifies the failure. Only an unavailable gateway is retried;
+// a declined, missing or conflicting payment would fail the same way again.
+// Other errors, such as timeouts, are wrapped as they are.
+func paymentError(message string, err error) error {
+	for _, t := range paymentErrorTypes {
+		if errors.Is(err, t.err) {
+			return temporal.NewApplicationErrorWithOptions(message, t.errType, temporal.ApplicationErrorOptions{
+				NonRetryable: !t.retryable,
+				Cause:        err,
+			})
+		}
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// Application error types returned by CalculateTax. An order shipped to a
+// jurisdiction the tax calculator does not cover fails with
+// UnsupportedTaxJurisdiction and is not retried.
+const (
+	UnsupportedTaxJurisdictionErrorType = "UnsupportedTaxJurisdiction"
+	InvalidTaxRequestErrorType          = "InvalidTaxRequest"
+	TaxUnavailableErrorType             = "TaxUnavailable"
+	UnexpectedTaxResponseErrorType      = "UnexpectedTaxResponse"
+)
+
+var taxErrorTypes = []struct {
+	err       error
+	errType   string
+	retryable bool
+}{
+	{tax.ErrUnsupportedJurisdiction, UnsupportedTaxJurisdictionErrorType, false},
+	{tax.ErrInvalidRequest, InvalidTaxRequestErrorType, false},
+	{tax.ErrUnavailable, TaxUnavailableErrorType, true},
+	{tax.ErrUnexpectedResponse, UnexpectedTaxResponseErrorType, false},
+}
+
+// taxError wraps an error from the TaxCalculator in an application error whose
+// type identifies the failure, retrying only an unavailable tax service. Other
+// errors, such as timeouts, are wrapped as they are.
+func taxError(message string, err error) error {
+	for _, t := range taxErrorTypes {
+		if errors.Is(err, t.err) {
+			return temporal.NewApplicationErrorWithOptions(message, t.errType, temporal.ApplicationErrorOptions{
+				NonRetryable: !t.retryable,
+				Cause:        err,
+			})
+		}
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
@@ -0,0 +1,239 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// FraudRejectedErrorType is the application error type of an order rejected by
+// fraud screening, either by the FraudScorer or by a reviewer. The error
+// details carry the FraudScreening. Such orders are not retried.
+const FraudRejectedErrorType = "FraudRejected"
+
+// Signals a reviewer sends to an order held for fraud review. Both take a
+// TransitionRequest recording who made the decision and why; one without an
+// actor is ignored. Decisions sent before the order is ON_HOLD are dropped
+// when it is held, so that nobody approves an order they have not reviewed.
+const (
+	ApproveOrderSignal = "approveOrder"
+	RejectOrderSignal  = "rejectOrder"
+)
+
+// defaultFraudReviewTimeout is how long an order waits for a reviewer when
+// Params does not specify it.
+const defaultFraudReviewTimeout = 24 * time.Hour
+
+// FraudDecision is the outcome of screening an order for fraud.
+type FraudDecision string
+
+const (
+	// FraudAccept lets the order proceed.
+	FraudAccept FraudDecision = "accept"
+	// FraudReject fails the order.
+	FraudReject FraudDecision = "reject"
+	// FraudReview holds the order ON_HOLD until a reviewer approves or
+	// rejects it.
+	FraudReview FraudDecision = "review"
+)
+
+func (d FraudDecision) valid() bool {
+	return d == FraudAccept || d == FraudReject || d == FraudReview
+}
+
+// FraudScorer assesses the risk that an order is fraudulent.
+type FraudScorer interface {
+	ScoreOrder(ctx context.Context, req ScreeningRequest) (FraudAssessment, error)
+}
+
+// ScreeningRequest is an order to be screened for fraud.
+type ScreeningRequest struct {
+	Order Order `json:"order"`
+	// Total is the amount the order will be charged, including tax and
+	// shipping, in the order's currency.
+	Total decimal.Decimal `json:"total"`
+}
+
+// FraudAssessment is a FraudScorer's verdict on an order.
+type FraudAssessment struct {
+	Decision FraudDecision `json:"decision"`
+	// Score is the risk the scorer found, higher being riskier. Its scale is
+	// up to the scorer.
+	Score int `json:"score"`
+	// Reasons explain the score, e.g. "billing and shipping countries differ".
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// FraudScreening is the outcome of screening an order, including any manual
+// review.
+type FraudScreening struct {
+	Assessment FraudAssessment `json:"assessment"`
+	// Decision is the final decision: the scorer's, or the reviewer's if the
+	// order was held for review.
+	Decision FraudDecision       `json:"decision"`
+	Review   *FraudReviewOutcome `json:"review,omitempty"`
+}
+
+// FraudReviewOutcome records the manual review of an order held by fraud
+// screening.
+type FraudReviewOutcome struct {
+	Decision FraudDecision `json:"decision"`
+	Actor    string        `json:"actor"`
+	Reason   string        `json:"reason,omitempty"`
+	At       time.Time     `json:"at"`
+	// TimedOut is set if no reviewer decided within the review timeout, in
+	// which case the order is rejected.
+	TimedOut bool `json:"timed_out,omitempty"`
+}
+
+// WithFraudScorer sets the scorer ScreenOrder uses. Without one, every order
+// is accepted.
+func WithFraudScorer(scorer FraudScorer) ActivityOption {
+	return func(a *OrderActivities) {
+		a.fraudScorer = scorer
+	}
+}
+
+// ScreenOrder assesses the risk that the priced and taxed order is
+// fraudulent.
+func (a *OrderActivities) ScreenOrder(ctx context.Context, order Order, pricing PriceBreakdown, tax TaxBreakdown) (FraudAssessment, error) {
+	if a.fraudScorer == nil {
+		return FraudAssessment{Decision: FraudAccept}, nil
+	}
+
+	req := ScreeningRequest{
+		Order: order,
+		Total: a.processingConfig.calculateTotals(order, pricing, tax).Total,
+	}
+	assessment, err := a.fraudScorer.ScoreOrder(ctx, req)
+	if err != nil {
+		return FraudAssessment{}, fmt.Errorf("failed to screen order %s: %w", order.ID, err)
+	}
+	if !assessment.Decision.valid() {
+		return FraudAssessment{}, fmt.Errorf("failed to screen order %s: unknown decision %q", order.ID, assessment.Decision)
+	}
+	return assessment, nil
+}
+
+// screen runs the ScreenOrder activity and holds the order for review if the
+// scorer asks for it. It returns nil once the order is accepted, and also if
+// the order was cancelled while it was on hold.
+func (w *orderWorkflow) screen(ctx workflow.Context, order Order, pricing PriceBreakdown, tax TaxBreakdown) error {
+	var orderActivities *OrderActivities
+	var assessment FraudAssessment
+	err := workflow.ExecuteActivity(ctx, orderActivities.ScreenOrder, order, pricing, tax).Get(ctx, &assessment)
+	if err != nil {
+		return err
+	}
+	w.fraud = &FraudScreening{Assessment: assessment, Decision: assessment.Decision}
+
+	switch assessment.Decision {
+	case FraudAccept:
+		return nil
+	case FraudReview:
+		return w.awaitReview(ctx)
+	default:
+		return w.fraudRejected("order rejected by fraud screening", strings.Join(assessment.Reasons, "; "))
+	}
+}
+
+// awaitReview keeps the order ON_HOLD until a reviewer approves or rejects it,
+// rejecting it if no decision arrives within Params.FraudReviewTimeout.
+func (w *orderWorkflow) awaitReview(ctx workflow.Context) error {
+	logger := workflow.GetLogger(ctx)
+
+	approvals := workflow.GetSignalChannel(ctx, ApproveOrderSignal)
+	rejections := workflow.GetSignalChannel(ctx, RejectOrderSignal)
+	drainReviews(ctx, approvals, rejections)
+
+	w.transition(ctx, OnHold, fraudReviewTrigger, TransitionRequest{
+		Actor:  systemActor,
+		Reason: strings.Join(w.fraud.Assessment.Reasons, "; "),
+	})
+
+	timeout := w.params.FraudReviewTimeout
+	if timeout <= 0 {
+		timeout = defaultFraudReviewTimeout
+	}
+
+	var review *FraudReviewOutcome
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		receive := func(decision FraudDecision) func(workflow.ReceiveChannel, bool) {
+			return func(c workflow.ReceiveChannel, more bool) {
+				var req TransitionRequest
+				c.Receive(ctx, &req)
+				if req.Actor == "" {
+					logger.Warn("Ignoring fraud review without an actor", "decision", decision)
+					return
+				}
+				review = &FraudReviewOutcome{
+					Decision: decision,
+					Actor:    req.Actor,
+					Reason:   req.Reason,
+					At:       workflow.Now(ctx),
+				}
+			}
+		}
+
+		selector := workflow.NewSelector(ctx)
+		selector.AddReceive(approvals, receive(FraudAccept))
+		selector.AddReceive(rejections, receive(FraudReject))
+		for review == nil {
+			selector.Select(ctx)
+		}
+	})
+
+	decided, err := workflow.AwaitWithTimeout(ctx, timeout, func() bool {
+		return review != nil || w.cancellation != nil
+	})
+	if err != nil {
+		return err
+	}
+	if w.cancellation != nil {
+		return nil
+	}
+	if !decided {
+		review = &FraudReviewOutcome{
+			Decision: FraudReject,
+			Actor:    systemActor,
+			Reason:   fmt.Sprintf("no review within %s", timeout),
+			At:       workflow.Now(ctx),
+			TimedOut: true,
+		}
+	}
+
+	logger.Info("Fraud review decided", "decision", review.Decision, "actor", review.Actor, "timedOut", review.TimedOut)
+	w.fraud.Review = review
+	w.fraud.Decision = review.Decision
+	if review.Decision == FraudReject {
+		return w.fraudRejected("order rejected on review by "+review.Actor, review.Reason)
+	}
+	return nil
+}
+
+// drainReviews drops the review decisions sent before the order was held.
+func drainReviews(ctx workflow.Context, channels ...workflow.ReceiveChannel) {
+	for _, c := range channels {
+		var req TransitionRequest
+		for c.ReceiveAsync(&req) {
+			workflow.GetLogger(ctx).Warn("Ignoring fraud review sent before the order was held",
+				"signal", c.Name(),
+				"actor", req.Actor,
+			)
+		}
+	}
+}
+
+// fraudRejected returns a non-retryable FraudRejected error carrying the
+// screening.
+func (w *orderWorkflow) fraudRejected(message, reason string) error {
+	if reason != "" {
+		message += ": " + reason
+	}
+	return temporal.NewNonRetryableApplicationError(message, FraudRejectedErrorType, nil, *w.fraud)
+}
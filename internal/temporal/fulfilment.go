@@ -0,0 +1,136 @@
+package temporal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ShipmentItem is a quantity of one product packed into a shipment.
+type ShipmentItem struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Quantity  int32     `json:"quantity"`
+}
+
+// ShipmentRequest is the argument of the shipItems update. It records a single
+// parcel leaving the warehouse with some or all of the outstanding items.
+type ShipmentRequest struct {
+	Actor          string         `json:"actor"`
+	Reason         string         `json:"reason,omitempty"`
+	TrackingNumber string         `json:"tracking_number"`
+	Items          []ShipmentItem `json:"items"`
+}
+
+// Shipment is a parcel that has left the warehouse.
+type Shipment struct {
+	TrackingNumber string         `json:"tracking_number,omitempty"`
+	Items          []ShipmentItem `json:"items"`
+	ShippedAt      time.Time      `json:"shipped_at"`
+	Actor          string         `json:"actor"`
+}
+
+// LineFulfilment tracks how much of a line item has been shipped.
+type LineFulfilment struct {
+	ProductID   uuid.UUID `json:"product_id"`
+	Ordered     int32     `json:"ordered"`
+	Shipped     int32     `json:"shipped"`
+	Outstanding int32     `json:"outstanding"`
+}
+
+func newFulfilment(items []LineItem) []LineFulfilment {
+	fulfilment := make([]LineFulfilment, 0, len(items))
+	for _, item := range items {
+		fulfilment = append(fulfilment, LineFulfilment{
+			ProductID:   item.ProductID,
+			Ordered:     item.Quantity,
+			Outstanding: item.Quantity,
+		})
+	}
+	return fulfilment
+}
+
+// fullyShipped reports whether every line item has left the warehouse.
+func (w *orderWorkflow) fullyShipped() bool {
+	for _, line := range w.fulfilment {
+		if line.Outstanding > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// outstandingItems returns everything that has not been shipped yet.
+func (w *orderWorkflow) outstandingItems() []ShipmentItem {
+	var items []ShipmentItem
+	for _, line := range w.fulfilment {
+		if line.Outstanding > 0 {
+			items = append(items, ShipmentItem{ProductID: line.ProductID, Quantity: line.Outstanding})
+		}
+	}
+	return items
+}
+
+// validateShipment rejects a shipment that contains products that are not in
+// the order or more of a product than is still outstanding.
+func (w *orderWorkflow) validateShipment(req ShipmentRequest) error {
+	if req.TrackingNumber == "" {
+		return fmt.Errorf("cannot %s: tracking number is required", ShipItemsUpdate)
+	}
+	if len(req.Items) == 0 {
+		return fmt.Errorf("cannot %s: shipment has no items", ShipItemsUpdate)
+	}
+
+	requested := make(map[uuid.UUID]int32, len(req.Items))
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("cannot %s: quantity of product %s must be positive", ShipItemsUpdate, item.ProductID)
+		}
+		requested[item.ProductID] += item.Quantity
+	}
+
+	outstanding := make(map[uuid.UUID]int32, len(w.fulfilment))
+	for _, line := range w.fulfilment {
+		outstanding[line.ProductID] += line.Outstanding
+	}
+	for productID, quantity := range requested {
+		if quantity > outstanding[productID] {
+			return fmt.Errorf("cannot %s: only %d of product %s left to ship", ShipItemsUpdate, outstanding[productID], productID)
+		}
+	}
+
+	return nil
+}
+
+// ship records a shipment and deducts its items from the outstanding
+// quantities, filling line items of the same product in order.
+func (w *orderWorkflow) ship(ctx workflow.Context, trackingNumber string, items []ShipmentItem, actor string) {
+	if len(items) == 0 {
+		return
+	}
+
+	for _, item := range items {
+		remaining := item.Quantity
+		for i := range w.fulfilment {
+			line := &w.fulfilment[i]
+			if remaining == 0 {
+				break
+			}
+			if line.ProductID != item.ProductID || line.Outstanding == 0 {
+				continue
+			}
+			shipped := min(remaining, line.Outstanding)
+			line.Shipped += shipped
+			line.Outstanding -= shipped
+			remaining -= shipped
+		}
+	}
+
+	w.shipments = append(w.shipments, Shipment{
+		TrackingNumber: trackingNumber,
+		Items:          items,
+		ShippedAt:      workflow.Now(ctx),
+		Actor:          actor,
+	})
+}
@@ -0,0 +1,118 @@
+package temporal
+
+import (
+	"time"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// Triggers recorded for transitions that are not caused by an update.
+const (
+	backorderedTrigger       = "insufficientInventory"
+	fraudReviewTrigger       = "fraudReview"
+	placedTrigger            = "inventoryReserved"
+	failureTrigger           = "failure"
+	returnFailureTrigger     = "returnFailure"
+	slaBreachTrigger         = "slaBreach"
+	workflowCancelledTrigger = "workflowCancelled"
+)
+
+// systemActor is recorded as the actor of transitions made by the workflow
+// itself rather than by an operator.
+const systemActor = "system"
+
+// StatusTransition records a single change of OrderStatus.
+type StatusTransition struct {
+	From OrderStatus `json:"from,omitempty"`
+	To   OrderStatus `json:"to"`
+	At   time.Time   `json:"at"`
+	// Trigger is the update that caused the transition, or the workflow event
+	// for transitions made by the system.
+	Trigger string `json:"trigger"`
+	Actor   string `json:"actor"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// OrderStatusQuery is the name of the query that returns an OrderStatusView.
+const OrderStatusQuery = "GetOrderStatus"
+
+// OrderStatusView is returned by the GetOrderStatus query.
+type OrderStatusView struct {
+	Status OrderStatus `json:"status"`
+	// Since is when the order entered its current status.
+	Since   time.Time          `json:"since"`
+	History []StatusTransition `json:"history"`
+	// StageDurations is how long the order has spent in each non-final
+	// status, including the time so far in the current one.
+	StageDurations map[OrderStatus]time.Duration `json:"stage_durations"`
+	// Allocations is the warehouse each line item is fulfilled from, if
+	// orders are routed to warehouses.
+	Allocations []Allocation `json:"allocations,omitempty"`
+	// Pricing is the itemised price of the order once it has been priced.
+	Pricing *PriceBreakdown `json:"pricing,omitempty"`
+	// Tax is the tax on each line item once the order has been taxed.
+	Tax *TaxBreakdown `json:"tax,omitempty"`
+	// Fraud is the outcome of fraud screening once the order has been
+	// screened, including any review.
+	Fraud *FraudScreening `json:"fraud,omitempty"`
+	// Processing holds the order totals once the order has been processed.
+	Processing *ProcessingResult `json:"processing,omitempty"`
+	// Fulfilment is the shipped and outstanding quantity of each line item.
+	Fulfilment []LineFulfilment `json:"fulfilment"`
+	Shipments  []Shipment       `json:"shipments"`
+	Return     *Return          `json:"return,omitempty"`
+	// Backorder is the order started for out of stock line items if the
+	// order was split.
+	Backorder *Backorder `json:"backorder,omitempty"`
+}
+
+// transition moves the order to a new status and records it in the history.
+func (w *orderWorkflow) transition(ctx workflow.Context, to OrderStatus, trigger string, req TransitionRequest) {
+	w.history = append(w.history, StatusTransition{
+		From:    w.status,
+		To:      to,
+		At:      workflow.Now(ctx),
+		Trigger: trigger,
+		Actor:   req.Actor,
+		Reason:  req.Reason,
+	})
+	w.status = to
+}
+
+// statusView builds the GetOrderStatus query result as of now.
+func (w *orderWorkflow) statusView(now time.Time) OrderStatusView {
+	view := OrderStatusView{
+		Status:         w.status,
+		History:        w.history,
+		StageDurations: make(map[OrderStatus]time.Duration, len(w.history)),
+		Allocations:    w.allocations,
+		Pricing:        w.pricing,
+		Tax:            w.tax,
+		Fraud:          w.fraud,
+		Processing:     w.processing,
+		Fulfilment:     w.fulfilment,
+		Shipments:      w.shipments,
+		Return:         w.returned,
+		Backorder:      w.backorder,
+	}
+	if view.History == nil {
+		view.History = []StatusTransition{}
+	}
+	if view.Shipments == nil {
+		view.Shipments = []Shipment{}
+	}
+
+	for i, t := range w.history {
+		view.Since = t.At
+		if t.To.final() {
+			continue
+		}
+		end := now
+		if i+1 < len(w.history) {
+			end = w.history[i+1].At
+		}
+		view.StageDurations[t.To] += end.Sub(t.At)
+	}
+
+	return view
+}
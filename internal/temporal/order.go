@@ -0,0 +1,114 @@
+package temporal
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// InvalidOrderErrorType is the application error type returned by Validate
+// for an order that is malformed or inconsistent. Such orders are not retried.
+// The error details are the []FieldViolation the order was rejected for.
+const InvalidOrderErrorType = "InvalidOrder"
+
+// Order is an order placed by a customer. Validate checks it against the
+// validate struct tags of its fields, see validation.go.
+type Order struct {
+	ID uuid.UUID `json:"id" validate:"nonnil_uuid"`
+	// CustomerID references the customer who placed the order.
+	CustomerID string `json:"customer_id,omitempty"`
+	// Currency is the ISO 4217 code of every amount in the order, e.g. "AUD".
+	Currency  string     `json:"currency" validate:"required,iso4217"`
+	LineItems []LineItem `json:"line_items" validate:"min=1,dive"`
+	// ShippingAddress is where the order is delivered. Orders are routed to
+	// the nearest warehouse by it.
+	ShippingAddress *Address `json:"shipping_address,omitempty"`
+	BillingAddress  *Address `json:"billing_address,omitempty"`
+	// CouponCodes are applied to the order by the pricing engine.
+	CouponCodes []string `json:"coupon_codes,omitempty" validate:"dive,required"`
+	// Totals are the amounts the customer was shown when placing the order.
+	// If given, Validate rejects the order unless they are in its currency
+	// and add up, and CalculateTax unless they match the amounts the order
+	// will be charged.
+	Totals *OrderTotals `json:"totals,omitempty"`
+}
+
+// Address is a postal address. Country is an ISO 3166-1 alpha-2 code and
+// Region the state or province within it.
+type Address struct {
+	Line1      string `json:"line1,omitempty"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city,omitempty"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country" validate:"required,iso3166_1_alpha2"`
+}
+
+type LineItem struct {
+	ProductID    uuid.UUID       `json:"product_id" validate:"nonnil_uuid"`
+	Quantity     int32           `json:"quantity" validate:"gt=0"`
+	PricePerItem decimal.Decimal `json:"price_per_item" validate:"positive_decimal"`
+	// Currency is the ISO 4217 code of PricePerItem. It defaults to the
+	// order's currency and must match it if given.
+	Currency string `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	// TaxCategory decides the tax rate of the product, e.g. "food". Products
+	// without one are taxed at the standard rate.
+	TaxCategory string `json:"tax_category,omitempty"`
+}
+
+// OrderTotals are the amounts charged for an order.
+type OrderTotals struct {
+	Currency string          `json:"currency"`
+	Subtotal decimal.Decimal `json:"subtotal"`
+	Discount decimal.Decimal `json:"discount"`
+	Tax      decimal.Decimal `json:"tax"`
+	Shipping decimal.Decimal `json:"shipping"`
+	Total    decimal.Decimal `json:"total"`
+}
+
+// checkOrder returns an InvalidOrder error listing every way in which order is
+// malformed or inconsistent, or nil if there is none. The totals are checked
+// against the amounts charged once the order is priced and taxed, by
+// CalculateTax.
+func checkOrder(order Order) error {
+	if violations := orderViolations(order); len(violations) > 0 {
+		return invalidOrder(violations)
+	}
+	return nil
+}
+
+// checkTotals returns the amounts in got that do not match want.
+func checkTotals(got, want OrderTotals) []FieldViolation {
+	var violations []FieldViolation
+	if got.Currency != want.Currency {
+		violations = append(violations, FieldViolation{
+			Field:   "totals.currency",
+			Rule:    "order_currency",
+			Param:   want.Currency,
+			Message: "must match the order currency " + want.Currency,
+		})
+	}
+
+	amounts := []struct {
+		field     string
+		got, want decimal.Decimal
+	}{
+		{"totals.subtotal", got.Subtotal, want.Subtotal},
+		{"totals.discount", got.Discount, want.Discount},
+		{"totals.tax", got.Tax, want.Tax},
+		{"totals.shipping", got.Shipping, want.Shipping},
+		{"totals.total", got.Total, want.Total},
+	}
+	for _, amount := range amounts {
+		if !amount.got.Equal(amount.want) {
+			violations = append(violations, FieldViolation{
+				Field:   amount.field,
+				Rule:    "calculated",
+				Param:   amount.want.String(),
+				Message: fmt.Sprintf("%s does not match the calculated %s", amount.got, amount.want),
+			})
+		}
+	}
+	return violations
+}
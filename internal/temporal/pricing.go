@@ -0,0 +1,108 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.temporal.io/sdk/temporal"
+)
+
+// UnknownCouponErrorType is the application error type returned by PriceOrder
+// for an order with a coupon code the pricing engine does not recognise. Such
+// orders are not retried.
+const UnknownCouponErrorType = "UnknownCoupon"
+
+// ErrUnknownCoupon is returned by a PricingEngine for a coupon code that is
+// not valid.
+var ErrUnknownCoupon = errors.New("unknown coupon code")
+
+// PricingEngine prices orders. It may re-price line items from a catalogue
+// and apply discounts and promotions.
+type PricingEngine interface {
+	// PriceOrder returns the price of every line item of the order, in line
+	// item order, and the discounts that apply to it. Coupon codes it does
+	// not recognise are reported with an error wrapping ErrUnknownCoupon.
+	PriceOrder(ctx context.Context, order Order) (PriceBreakdown, error)
+}
+
+// PriceBreakdown is an itemised price of an order, before tax and shipping.
+type PriceBreakdown struct {
+	Lines []LinePrice `json:"lines"`
+	// Discounts apply to the order as a whole, after line discounts.
+	Discounts []Discount `json:"discounts,omitempty"`
+	// FreeShipping waives the shipping fee, whatever the subtotal.
+	FreeShipping bool `json:"free_shipping,omitempty"`
+	// Coupons are the coupon codes of the order that were applied.
+	Coupons []string `json:"coupons,omitempty"`
+}
+
+// LinePrice is the price of a line item.
+type LinePrice struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Quantity  int32     `json:"quantity"`
+	// UnitPrice is the price of one item before discounts.
+	UnitPrice decimal.Decimal `json:"unit_price"`
+	Discounts []Discount      `json:"discounts,omitempty"`
+	// Total is the price of the line after its discounts.
+	Total decimal.Decimal `json:"total"`
+}
+
+// Discount is an amount taken off a line item or an order.
+type Discount struct {
+	// Promotion names the promotion or coupon code that gave the discount.
+	Promotion   string          `json:"promotion"`
+	Description string          `json:"description,omitempty"`
+	Amount      decimal.Decimal `json:"amount"`
+}
+
+// WithPricingEngine sets the engine PriceOrder uses. Without one, orders are
+// charged the prices given in their line items and coupon codes are ignored.
+func WithPricingEngine(engine PricingEngine) ActivityOption {
+	return func(a *OrderActivities) {
+		a.pricingEngine = engine
+	}
+}
+
+// PriceOrder prices the order with the pricing engine.
+func (a *OrderActivities) PriceOrder(ctx context.Context, order Order) (PriceBreakdown, error) {
+	pricing := priceAsGiven(order)
+	if a.pricingEngine != nil {
+		var err error
+		pricing, err = a.pricingEngine.PriceOrder(ctx, order)
+		if errors.Is(err, ErrUnknownCoupon) {
+			return PriceBreakdown{}, temporal.NewNonRetryableApplicationError(err.Error(), UnknownCouponErrorType, err)
+		}
+		if err != nil {
+			return PriceBreakdown{}, fmt.Errorf("failed to price order %s: %w", order.ID, err)
+		}
+	}
+
+	return pricing, nil
+}
+
+// priceAsGiven prices every line item of order at its PricePerItem, without
+// discounts.
+func priceAsGiven(order Order) PriceBreakdown {
+	pricing := PriceBreakdown{Lines: make([]LinePrice, 0, len(order.LineItems))}
+	for _, item := range order.LineItems {
+		pricing.Lines = append(pricing.Lines, LinePrice{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.PricePerItem,
+			Total:     item.PricePerItem.Mul(decimal.NewFromInt32(item.Quantity)).Round(2),
+		})
+	}
+	return pricing
+}
+
+// discountTotal sums the amounts of discounts.
+func discountTotal(discounts []Discount) decimal.Decimal {
+	total := decimal.Zero
+	for _, d := range discounts {
+		total = total.Add(d.Amount)
+	}
+	return total
+}
@@ -0,0 +1,112 @@
+package temporal
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ProcessingConfig sets the charges added to an order when it is processed.
+type ProcessingConfig struct {
+	// TaxRate is applied to every line item, e.g. "0.10" for 10%, unless a
+	// TaxCalculator is configured.
+	TaxRate decimal.Decimal `yaml:"taxRate"`
+	// ShippingFee is the flat shipping charge per order.
+	ShippingFee decimal.Decimal `yaml:"shippingFee"`
+	// FreeShippingThreshold waives the shipping fee for orders whose subtotal
+	// is at least this amount. Zero disables free shipping.
+	FreeShippingThreshold decimal.Decimal `yaml:"freeShippingThreshold"`
+}
+
+// LineTotal is the priced form of a LineItem.
+type LineTotal struct {
+	ProductID    uuid.UUID       `json:"product_id"`
+	Quantity     int32           `json:"quantity"`
+	PricePerItem decimal.Decimal `json:"price_per_item"`
+	// Discount is the amount taken off the line by promotions.
+	Discount decimal.Decimal `json:"discount"`
+	Total    decimal.Decimal `json:"total"`
+	// Tax is the tax charged on the line, on top of Total.
+	Tax decimal.Decimal `json:"tax"`
+}
+
+// ProcessingResult is the outcome of processing an order. Amounts are in the
+// order's currency.
+type ProcessingResult struct {
+	OrderID  uuid.UUID   `json:"order_id"`
+	Currency string      `json:"currency"`
+	Lines    []LineTotal `json:"lines"`
+	// Subtotal is the sum of the line totals.
+	Subtotal decimal.Decimal `json:"subtotal"`
+	// Discount is the amount taken off the subtotal by order promotions. Tax
+	// is charged on the discounted subtotal.
+	Discount decimal.Decimal `json:"discount"`
+	Tax      decimal.Decimal `json:"tax"`
+	// TaxJurisdiction is where the tax was charged, if the tax calculator
+	// reports it.
+	TaxJurisdiction string          `json:"tax_jurisdiction,omitempty"`
+	Shipping        decimal.Decimal `json:"shipping"`
+	Total           decimal.Decimal `json:"total"`
+	ProcessedAt     time.Time       `json:"processed_at"`
+}
+
+// OrderRecord is what an OrderRepository persists for a processed order.
+type OrderRecord struct {
+	Order      Order            `json:"order"`
+	Pricing    PriceBreakdown   `json:"pricing"`
+	Tax        TaxBreakdown     `json:"tax"`
+	Processing ProcessingResult `json:"processing"`
+}
+
+// calculateTotals totals the priced line items, takes off the order discounts
+// and adds the tax and shipping. Amounts are rounded to two decimal places.
+func (c ProcessingConfig) calculateTotals(order Order, pricing PriceBreakdown, tax TaxBreakdown) ProcessingResult {
+	result := ProcessingResult{
+		OrderID:         order.ID,
+		Currency:        order.Currency,
+		Lines:           make([]LineTotal, 0, len(pricing.Lines)),
+		Subtotal:        decimal.Zero,
+		Tax:             tax.Total.Round(2),
+		TaxJurisdiction: tax.Jurisdiction,
+	}
+
+	for i, line := range pricing.Lines {
+		lineTotal := LineTotal{
+			ProductID:    line.ProductID,
+			Quantity:     line.Quantity,
+			PricePerItem: line.UnitPrice,
+			Discount:     discountTotal(line.Discounts).Round(2),
+			Total:        line.Total.Round(2),
+		}
+		if i < len(tax.Lines) {
+			lineTotal.Tax = tax.Lines[i].Amount.Round(2)
+		}
+		result.Lines = append(result.Lines, lineTotal)
+		result.Subtotal = result.Subtotal.Add(lineTotal.Total)
+	}
+
+	result.Discount = decimal.Min(discountTotal(pricing.Discounts).Round(2), result.Subtotal)
+	discounted := result.Subtotal.Sub(result.Discount)
+
+	result.Shipping = c.ShippingFee.Round(2)
+	if pricing.FreeShipping || (c.FreeShippingThreshold.IsPositive() && discounted.GreaterThanOrEqual(c.FreeShippingThreshold)) {
+		result.Shipping = decimal.Zero
+	}
+
+	result.Total = discounted.Add(result.Tax).Add(result.Shipping)
+
+	return result
+}
+
+// totals returns the amounts charged for the order.
+func (r ProcessingResult) totals() OrderTotals {
+	return OrderTotals{
+		Currency: r.Currency,
+		Subtotal: r.Subtotal,
+		Discount: r.Discount,
+		Tax:      r.Tax,
+		Shipping: r.Shipping,
+		Total:    r.Total,
+	}
+}
@@ -0,0 +1,157 @@
+package temporal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ReturnRequest is the argument of the requestReturn update. It lists the
+// delivered items the customer is sending back.
+type ReturnRequest struct {
+	Actor  string         `json:"actor"`
+	Reason string         `json:"reason,omitempty"`
+	Items  []ShipmentItem `json:"items"`
+}
+
+// Return records the items sent back after delivery and the amount refunded
+// for them.
+type Return struct {
+	Items        []ShipmentItem  `json:"items"`
+	Reason       string          `json:"reason,omitempty"`
+	RequestedAt  time.Time       `json:"requested_at"`
+	ReceivedAt   *time.Time      `json:"received_at,omitempty"`
+	RefundAmount decimal.Decimal `json:"refund_amount"`
+}
+
+// awaitReturn keeps a delivered order open for the return window. If a return
+// is requested in time it waits for the items to be received, restocks them
+// and refunds the customer.
+func (w *orderWorkflow) awaitReturn(ctx workflow.Context) (Result, error) {
+	logger := workflow.GetLogger(ctx)
+	order := w.params.Order
+
+	w.returnWindowOpen = true
+	requested, err := workflow.AwaitWithTimeout(ctx, w.params.ReturnWindow, func() bool {
+		return w.returned != nil
+	})
+	w.returnWindowOpen = false
+	if err != nil || !requested {
+		return Result{Status: w.status}, err
+	}
+
+	// Wait for the returned items to arrive at the warehouse.
+	if err := workflow.Await(ctx, func() bool { return w.returned.ReceivedAt != nil }); err != nil {
+		return Result{Status: w.status, Return: w.returned}, err
+	}
+	defer func() { w.returnSettled = true }()
+
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions)
+
+	var orderActivities *OrderActivities
+	items := make([]LineItem, 0, len(w.returned.Items))
+	for _, item := range w.returned.Items {
+		items = append(items, LineItem{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+	err = workflow.ExecuteActivity(ctx, orderActivities.RestockInventory, order.ID, items, w.allocations).Get(ctx, nil)
+	if err != nil {
+		return w.returnFailed(ctx, fmt.Errorf("failed to restock returned items: %w", err))
+	}
+
+	err = workflow.ExecuteActivity(ctx, orderActivities.RefundPayment, order.ID, w.returned.RefundAmount).Get(ctx, nil)
+	if err != nil {
+		return w.returnFailed(ctx, fmt.Errorf("failed to refund returned items: %w", err))
+	}
+	logger.Info("Return refunded", "amount", w.returned.RefundAmount)
+
+	w.transition(ctx, Refunded, ReceiveReturnUpdate, w.returnReceivedBy)
+	return Result{Status: w.status, Return: w.returned}, nil
+}
+
+// returnFailed moves an order whose returned items could not be restocked or
+// refunded to RETURN_FAILED for manual follow-up. The receiveReturn update
+// fails with the same error as the workflow.
+func (w *orderWorkflow) returnFailed(ctx workflow.Context, cause error) (Result, error) {
+	workflow.GetLogger(ctx).Error("Unable to settle return", "error", cause)
+
+	w.transition(ctx, ReturnFailed, returnFailureTrigger, TransitionRequest{Actor: systemActor, Reason: cause.Error()})
+	w.returnErr = temporal.NewApplicationErrorWithCause("unable to settle return", ReturnFailedErrorType, cause)
+	result := Result{Status: w.status, Return: w.returned}
+	return result, temporal.NewApplicationErrorWithCause("unable to settle return", ReturnFailedErrorType, cause, result)
+}
+
+// validateReturn rejects a return outside the return window or for items that
+// were never shipped.
+func (w *orderWorkflow) validateReturn(req ReturnRequest) error {
+	if err := w.validateTransition(RequestReturnUpdate, Completed); err != nil {
+		return err
+	}
+	if !w.returnWindowOpen {
+		return fmt.Errorf("cannot %s: return window has closed", RequestReturnUpdate)
+	}
+	if req.Actor == "" {
+		return fmt.Errorf("cannot %s: actor is required", RequestReturnUpdate)
+	}
+	if len(req.Items) == 0 {
+		return fmt.Errorf("cannot %s: return has no items", RequestReturnUpdate)
+	}
+
+	requested := make(map[uuid.UUID]int32, len(req.Items))
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("cannot %s: quantity of product %s must be positive", RequestReturnUpdate, item.ProductID)
+		}
+		requested[item.ProductID] += item.Quantity
+	}
+
+	shipped := make(map[uuid.UUID]int32, len(w.fulfilment))
+	for _, line := range w.fulfilment {
+		shipped[line.ProductID] += line.Shipped
+	}
+	for productID, quantity := range requested {
+		if quantity > shipped[productID] {
+			return fmt.Errorf("cannot %s: only %d of product %s were shipped", RequestReturnUpdate, shipped[productID], productID)
+		}
+	}
+
+	return nil
+}
+
+// refundAmount prices the returned items at what was paid for them, after
+// line and order discounts, and adds the tax charged on them. Shipping is not
+// refunded.
+func (w *orderWorkflow) refundAmount(items []ShipmentItem) decimal.Decimal {
+	if w.processing == nil {
+		return decimal.Zero
+	}
+
+	lines := make(map[uuid.UUID]LineTotal, len(w.processing.Lines))
+	for _, line := range w.processing.Lines {
+		if _, ok := lines[line.ProductID]; !ok {
+			lines[line.ProductID] = line
+		}
+	}
+
+	amount, tax := decimal.Zero, decimal.Zero
+	for _, item := range items {
+		line, ok := lines[item.ProductID]
+		if !ok || line.Quantity <= 0 {
+			continue
+		}
+		share := decimal.NewFromInt32(item.Quantity).Div(decimal.NewFromInt32(line.Quantity))
+		amount = amount.Add(line.Total.Mul(share))
+		tax = tax.Add(line.Tax.Mul(share))
+	}
+
+	if w.processing.Discount.IsPositive() && w.processing.Subtotal.IsPositive() {
+		discounted := w.processing.Subtotal.Sub(w.processing.Discount)
+		amount = amount.Mul(discounted).Div(w.processing.Subtotal)
+	}
+	amount = amount.Round(2).Add(tax.Round(2))
+
+	return amount
+}
@@ -0,0 +1,261 @@
+package temporal
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/temporal"
+)
+
+// RoutingStrategy decides which warehouse fulfils each line item of an order
+// when several warehouses have it in stock.
+type RoutingStrategy string
+
+const (
+	// RouteByPriority fulfils each line item from the highest priority
+	// warehouse that has it in stock.
+	RouteByPriority RoutingStrategy = "priority"
+	// RouteToNearest fulfils each line item from the warehouse nearest to the
+	// shipping address that has it in stock. A warehouse in the same region
+	// is nearer than one elsewhere in the same country, which is nearer than
+	// one abroad. Ties, and orders without a shipping address, are routed by
+	// priority.
+	RouteToNearest RoutingStrategy = "nearest"
+	// RouteFewestSplits fulfils the order from as few warehouses as possible,
+	// preferring warehouses by priority when several would do.
+	RouteFewestSplits RoutingStrategy = "fewest-splits"
+)
+
+// Warehouse is a fulfilment location with its own inventory service.
+type Warehouse struct {
+	Name string
+	// Priority ranks warehouses, lowest first, when routing by priority and
+	// to break ties between the other strategies.
+	Priority int
+	// Country and Region locate the warehouse for RouteToNearest. They are
+	// compared with the shipping address case-insensitively.
+	Country string
+	Region  string
+	// Inventory checks the warehouse's stock, in one call per order if it
+	// also implements BatchInventoryChecker.
+	Inventory InventoryChecker
+	// Reserver holds the warehouse's stock for an order. The reserver set with
+	// WithInventoryReserver is used if it is nil.
+	Reserver InventoryReserver
+}
+
+// Allocation assigns a line item to the warehouse it is fulfilled from.
+type Allocation struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Quantity  int32     `json:"quantity"`
+	Warehouse string    `json:"warehouse"`
+}
+
+// WithWarehouses makes Validate route every line item to one of warehouses
+// using strategy, and the inventory activities reserve, commit and release
+// stock in the warehouse each item was allocated to. Without warehouses
+// orders are checked and reserved with the clients given to
+// NewOrderActivities and not allocated.
+func WithWarehouses(strategy RoutingStrategy, warehouses ...Warehouse) ActivityOption {
+	return func(a *OrderActivities) {
+		a.routing = strategy
+		a.warehouses = slices.Clone(warehouses)
+		slices.SortStableFunc(a.warehouses, func(x, y Warehouse) int {
+			return cmp.Or(cmp.Compare(x.Priority, y.Priority), strings.Compare(x.Name, y.Name))
+		})
+	}
+}
+
+// allocate checks the stock of every warehouse and routes each line item to
+// one that can fulfil it. Products no warehouse has enough of are returned
+// instead.
+func (a *OrderActivities) allocate(ctx context.Context, order Order, quantities map[uuid.UUID]int32) ([]Allocation, []uuid.UUID, error) {
+	// stocked[i] holds the products warehouse i can supply in full.
+	stocked := make([]map[uuid.UUID]bool, len(a.warehouses))
+	for i, wh := range a.warehouses {
+		batch, _ := wh.Inventory.(BatchInventoryChecker)
+		available, err := checkInventory(ctx, wh.Inventory, batch, quantities, wh.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		stocked[i] = available
+	}
+
+	var unavailable []uuid.UUID
+	candidates := make(map[uuid.UUID][]int, len(quantities))
+	for _, productID := range sortedProductIDs(quantities) {
+		for i := range a.warehouses {
+			if stocked[i][productID] {
+				candidates[productID] = append(candidates[productID], i)
+			}
+		}
+		if len(candidates[productID]) == 0 {
+			unavailable = append(unavailable, productID)
+		}
+	}
+	if len(unavailable) > 0 {
+		return nil, unavailable, nil
+	}
+
+	var routes map[uuid.UUID]int
+	switch a.routing {
+	case RouteToNearest:
+		routes = routeToNearest(a.warehouses, candidates, order.ShippingAddress)
+	case RouteFewestSplits:
+		routes = routeFewestSplits(candidates)
+	default:
+		routes = routeByPriority(candidates)
+	}
+
+	allocations := make([]Allocation, 0, len(order.LineItems))
+	for _, item := range order.LineItems {
+		allocations = append(allocations, Allocation{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Warehouse: a.warehouses[routes[item.ProductID]].Name,
+		})
+	}
+	return allocations, nil, nil
+}
+
+// routeByPriority picks the first candidate of each product. Candidates are in
+// priority order.
+func routeByPriority(candidates map[uuid.UUID][]int) map[uuid.UUID]int {
+	routes := make(map[uuid.UUID]int, len(candidates))
+	for productID, warehouses := range candidates {
+		routes[productID] = warehouses[0]
+	}
+	return routes
+}
+
+// routeToNearest picks the candidate of each product nearest to address.
+func routeToNearest(warehouses []Warehouse, candidates map[uuid.UUID][]int, address *Address) map[uuid.UUID]int {
+	routes := make(map[uuid.UUID]int, len(candidates))
+	for productID, indexes := range candidates {
+		// SortStable keeps candidates at the same distance in priority order.
+		nearest := slices.Clone(indexes)
+		slices.SortStableFunc(nearest, func(x, y int) int {
+			return cmp.Compare(distance(warehouses[x], address), distance(warehouses[y], address))
+		})
+		routes[productID] = nearest[0]
+	}
+	return routes
+}
+
+// distance ranks how far a warehouse is from an address: 0 in the same
+// region, 1 in the same country and 2 otherwise.
+func distance(wh Warehouse, address *Address) int {
+	if address == nil || address.Country == "" || !strings.EqualFold(wh.Country, address.Country) {
+		return 2
+	}
+	if address.Region == "" || !strings.EqualFold(wh.Region, address.Region) {
+		return 1
+	}
+	return 0
+}
+
+// routeFewestSplits repeatedly picks the warehouse that can supply the most
+// products not yet routed, preferring higher priority warehouses on ties.
+func routeFewestSplits(candidates map[uuid.UUID][]int) map[uuid.UUID]int {
+	routes := make(map[uuid.UUID]int, len(candidates))
+	for len(routes) < len(candidates) {
+		supplies := make(map[int]int)
+		for productID, warehouses := range candidates {
+			if _, ok := routes[productID]; ok {
+				continue
+			}
+			for _, i := range warehouses {
+				supplies[i]++
+			}
+		}
+
+		best := -1
+		for i, n := range supplies {
+			if best < 0 || n > supplies[best] || (n == supplies[best] && i < best) {
+				best = i
+			}
+		}
+
+		for productID, warehouses := range candidates {
+			if _, ok := routes[productID]; !ok && slices.Contains(warehouses, best) {
+				routes[productID] = best
+			}
+		}
+	}
+	return routes
+}
+
+// allocatedQuantities groups the allocated quantity of each product by
+// warehouse.
+func allocatedQuantities(allocations []Allocation) map[string]map[uuid.UUID]int32 {
+	byWarehouse := make(map[string]map[uuid.UUID]int32)
+	for _, allocation := range allocations {
+		if byWarehouse[allocation.Warehouse] == nil {
+			byWarehouse[allocation.Warehouse] = make(map[uuid.UUID]int32)
+		}
+		byWarehouse[allocation.Warehouse][allocation.ProductID] += allocation.Quantity
+	}
+	return byWarehouse
+}
+
+// allocatedWarehouses returns the warehouses the order was allocated to, in
+// name order.
+func allocatedWarehouses(allocations []Allocation) []string {
+	var names []string
+	for _, allocation := range allocations {
+		if !slices.Contains(names, allocation.Warehouse) {
+			names = append(names, allocation.Warehouse)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// restockQuantities groups the quantity of each returned product by the
+// warehouse it was allocated to.
+func restockQuantities(items []LineItem, allocations []Allocation) map[string]map[uuid.UUID]int32 {
+	warehouses := make(map[uuid.UUID]string, len(allocations))
+	for _, allocation := range allocations {
+		warehouses[allocation.ProductID] = allocation.Warehouse
+	}
+
+	byWarehouse := make(map[string]map[uuid.UUID]int32)
+	for _, item := range items {
+		warehouse := warehouses[item.ProductID]
+		if byWarehouse[warehouse] == nil {
+			byWarehouse[warehouse] = make(map[uuid.UUID]int32)
+		}
+		byWarehouse[warehouse][item.ProductID] += item.Quantity
+	}
+	return byWarehouse
+}
+
+// inWarehouse describes where an inventory call was made in error messages.
+func inWarehouse(warehouse string) string {
+	if warehouse == "" {
+		return ""
+	}
+	return " in warehouse " + warehouse
+}
+
+// reserverFor returns the reserver of the named warehouse.
+func (a *OrderActivities) reserverFor(warehouse string) (InventoryReserver, error) {
+	for _, wh := range a.warehouses {
+		if wh.Name != warehouse {
+			continue
+		}
+		if wh.Reserver != nil {
+			return wh.Reserver, nil
+		}
+		return a.inventoryReserver, nil
+	}
+	return nil, temporal.NewNonRetryableApplicationError(
+		"unknown warehouse",
+		UnknownWarehouseErrorType,
+		fmt.Errorf("unknown warehouse %q", warehouse),
+	)
+}
@@ -0,0 +1,97 @@
+package temporal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/workflow"
+)
+
+// SLA sets how long an order may wait in each stage before it is escalated.
+// A zero deadline disables the timer for that stage, so different customer
+// tiers can be given different SLAs through the workflow Params.
+type SLA struct {
+	// PickWithin is the deadline for a placed order to be picked.
+	PickWithin time.Duration `json:"pick_within,omitempty"`
+	// ShipWithin is the deadline for a picked order to be shipped.
+	ShipWithin time.Duration `json:"ship_within,omitempty"`
+	// DeliverWithin is the deadline for a shipped order to be delivered.
+	DeliverWithin time.Duration `json:"deliver_within,omitempty"`
+	// AutoCancel cancels an order that breaches its pick or ship deadline.
+	// Orders that have shipped, even partially, can no longer be cancelled and
+	// are only escalated.
+	AutoCancel bool `json:"auto_cancel,omitempty"`
+}
+
+// deadline returns how long an order may stay in the given status.
+func (s SLA) deadline(status OrderStatus) time.Duration {
+	switch status {
+	case Placed:
+		return s.PickWithin
+	case Picked:
+		return s.ShipWithin
+	case Shipped:
+		return s.DeliverWithin
+	default:
+		return 0
+	}
+}
+
+// Escalation describes an order that has stayed in a stage past its SLA.
+type Escalation struct {
+	OrderID    uuid.UUID     `json:"order_id"`
+	Stage      OrderStatus   `json:"stage"`
+	Deadline   time.Duration `json:"deadline"`
+	EnteredAt  time.Time     `json:"entered_at"`
+	AutoCancel bool          `json:"auto_cancel"`
+}
+
+// awaitWithSLA blocks until condition is true. If the stage's SLA deadline
+// passes first the breach is escalated and, when the SLA allows it, the order
+// is cancelled; otherwise it keeps waiting. It reports false if the order was
+// auto-cancelled.
+func (w *orderWorkflow) awaitWithSLA(ctx workflow.Context, stage OrderStatus, condition func() bool) (bool, error) {
+	deadline := w.params.SLA.deadline(stage)
+	if deadline <= 0 {
+		return true, workflow.Await(ctx, condition)
+	}
+
+	enteredAt := workflow.Now(ctx)
+	ok, err := workflow.AwaitWithTimeout(ctx, deadline, condition)
+	if err != nil || ok {
+		return true, err
+	}
+
+	autoCancel := w.params.SLA.AutoCancel && stage.cancellable() && len(w.shipments) == 0
+	w.escalate(ctx, Escalation{
+		OrderID:    w.params.Order.ID,
+		Stage:      stage,
+		Deadline:   deadline,
+		EnteredAt:  enteredAt,
+		AutoCancel: autoCancel,
+	})
+
+	// The order may have moved on while the breach was being escalated.
+	if autoCancel && !condition() {
+		w.recordCancellation(slaBreachTrigger, TransitionRequest{
+			Actor:  systemActor,
+			Reason: fmt.Sprintf("SLA breached: order was %s for longer than %s", stage, deadline),
+		})
+		return false, nil
+	}
+
+	return true, workflow.Await(ctx, condition)
+}
+
+// escalate runs the escalation activity. A failed escalation is logged rather
+// than failing the order.
+func (w *orderWorkflow) escalate(ctx workflow.Context, escalation Escalation) {
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions)
+
+	var orderActivities *OrderActivities
+	err := workflow.ExecuteActivity(ctx, orderActivities.Escalate, escalation).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Error("Failed to escalate SLA breach", "stage", escalation.Stage, "error", err)
+	}
+}
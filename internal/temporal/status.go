@@ -3,19 +3,42 @@ package temporal
 type OrderStatus string
 
 const (
+	Backordered      OrderStatus = "BACKORDERED"
+	OnHold           OrderStatus = "ON_HOLD"
 	Placed           OrderStatus = "PLACED"
 	Picked           OrderStatus = "PICKED"
 	Shipped          OrderStatus = "SHIPPED"
 	Completed        OrderStatus = "COMPLETED"
 	Cancelled        OrderStatus = "CANCELLED"
 	UnableToComplete OrderStatus = "UNABLE_TO_COMPLETE"
+	ReturnRequested  OrderStatus = "RETURN_REQUESTED"
+	Refunded         OrderStatus = "REFUNDED"
+	// ReturnFailed is a return whose items could not be restocked or
+	// refunded, which needs manual follow-up.
+	ReturnFailed OrderStatus = "RETURN_FAILED"
 )
 
 func (os OrderStatus) Valid() bool {
 	switch os {
-	case Placed, Picked, Shipped, Completed, Cancelled, UnableToComplete:
+	case Backordered, OnHold, Placed, Picked, Shipped, Completed, Cancelled, UnableToComplete, ReturnRequested, Refunded, ReturnFailed:
 		return true
 	default:
 		return false
 	}
 }
+
+// final reports whether the order has reached a status it cannot leave. A
+// completed order only leaves it if a return is requested.
+func (os OrderStatus) final() bool {
+	switch os {
+	case Completed, Cancelled, UnableToComplete, Refunded, ReturnFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// cancellable reports whether an order in this status can still be cancelled.
+func (os OrderStatus) cancellable() bool {
+	return os == Backordered || os == OnHold || os == Placed || os == Picked
+}
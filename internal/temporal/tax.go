@@ -0,0 +1,149 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/pulinau/demo-temporal-order-processor/internal/integrations/tax"
+	"github.com/shopspring/decimal"
+)
+
+// TaxCalculator computes the tax on an order.
+type TaxCalculator interface {
+	// CalculateTax returns the tax on every line of the request, in line
+	// order. Requests for a jurisdiction it does not cover, or a tax
+	// category it has no rate for, are reported with an error wrapping
+	// tax.ErrUnsupportedJurisdiction; other failures the calculator reports
+	// wrap one of the tax package's other errors.
+	CalculateTax(ctx context.Context, req TaxRequest) (TaxBreakdown, error)
+}
+
+// The tax types are declared by the tax package, which calculators share.
+type (
+	// TaxRequest is the taxable amounts of an order.
+	TaxRequest = tax.Request
+	// TaxableLine is the amount a line item is taxed on.
+	TaxableLine = tax.TaxableLine
+	// TaxBreakdown is the tax charged on an order.
+	TaxBreakdown = tax.Breakdown
+	// LineTax is the tax charged on a line item.
+	LineTax = tax.LineTax
+)
+
+// WithTaxCalculator sets the calculator CalculateTax uses. Without one, every
+// line item is taxed at ProcessingConfig.TaxRate.
+func WithTaxCalculator(calculator TaxCalculator) ActivityOption {
+	return func(a *OrderActivities) {
+		a.taxCalculator = calculator
+	}
+}
+
+// CalculateTax computes the tax on every line item of the priced order. As
+// every amount the order is charged is known once it is taxed, the totals the
+// customer was shown, if any, are checked here.
+func (a *OrderActivities) CalculateTax(ctx context.Context, order Order, pricing PriceBreakdown) (TaxBreakdown, error) {
+	req := taxRequest(order, pricing)
+
+	tax := a.flatTax(req)
+	if a.taxCalculator != nil {
+		var err error
+		tax, err = a.taxCalculator.CalculateTax(ctx, req)
+		if err != nil {
+			return TaxBreakdown{}, taxError(fmt.Sprintf("failed to calculate tax for order %s", order.ID), err)
+		}
+	}
+
+	// The order is charged the sum of the line taxes, whatever total the
+	// calculator reports.
+	tax.Total = decimal.Zero
+	for i := range tax.Lines {
+		tax.Lines[i].Amount = tax.Lines[i].Amount.Round(2)
+		tax.Total = tax.Total.Add(tax.Lines[i].Amount)
+	}
+
+	if order.Totals != nil {
+		want := a.processingConfig.calculateTotals(order, pricing, tax).totals()
+		if violations := checkTotals(*order.Totals, want); len(violations) > 0 {
+			return TaxBreakdown{}, invalidOrder(violations)
+		}
+	}
+
+	return tax, nil
+}
+
+// flatTax taxes every line of req at the configured tax rate.
+func (a *OrderActivities) flatTax(req TaxRequest) TaxBreakdown {
+	tax := TaxBreakdown{Lines: make([]LineTax, 0, len(req.Lines))}
+	for _, line := range req.Lines {
+		tax.Lines = append(tax.Lines, LineTax{
+			ProductID:   line.ProductID,
+			TaxCategory: line.TaxCategory,
+			Taxable:     line.Amount,
+			Rate:        a.processingConfig.TaxRate,
+			Amount:      line.Amount.Mul(a.processingConfig.TaxRate).Round(2),
+		})
+	}
+	return tax
+}
+
+// taxRequest returns the taxable amount of every line of the priced order.
+// Order discounts are shared between the lines in proportion to their totals,
+// the last line taking any rounding difference.
+func taxRequest(order Order, pricing PriceBreakdown) TaxRequest {
+	req := TaxRequest{
+		OrderID:  order.ID,
+		Currency: order.Currency,
+		Address:  taxAddress(order.ShippingAddress),
+		Lines:    make([]TaxableLine, 0, len(pricing.Lines)),
+	}
+	if req.Address == nil {
+		req.Address = taxAddress(order.BillingAddress)
+	}
+
+	categories := make(map[uuid.UUID]string, len(order.LineItems))
+	for _, item := range order.LineItems {
+		categories[item.ProductID] = item.TaxCategory
+	}
+
+	subtotal := decimal.Zero
+	for _, line := range pricing.Lines {
+		subtotal = subtotal.Add(line.Total.Round(2))
+	}
+	discount := decimal.Min(discountTotal(pricing.Discounts).Round(2), subtotal)
+
+	remaining := discount
+	for i, line := range pricing.Lines {
+		amount := line.Total.Round(2)
+		if discount.IsPositive() {
+			share := remaining
+			if i < len(pricing.Lines)-1 {
+				share = discount.Mul(amount).Div(subtotal).Round(2)
+			}
+			remaining = remaining.Sub(share)
+			amount = amount.Sub(share)
+		}
+		req.Lines = append(req.Lines, TaxableLine{
+			ProductID:   line.ProductID,
+			TaxCategory: categories[line.ProductID],
+			Amount:      amount,
+		})
+	}
+	return req
+}
+
+// taxAddress returns address as the tax package takes it, or nil if address
+// is nil.
+func taxAddress(address *Address) *tax.Address {
+	if address == nil {
+		return nil
+	}
+	return &tax.Address{
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		Region:     address.Region,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}
+}
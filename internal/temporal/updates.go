@@ -0,0 +1,204 @@
+package temporal
+
+import (
+	"fmt"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// Define updates. Each update takes a TransitionRequest, moves the order to its
+// next status and returns the status the order ended up in. ShipItemsUpdate
+// takes a ShipmentRequest instead and only moves the order to Shipped once
+// every item has left the warehouse; RequestReturnUpdate takes a
+// ReturnRequest.
+const (
+	PickOrderUpdate      = "pickOrder"
+	ShipOrderUpdate      = "shipOrder"
+	ShipItemsUpdate      = "shipItems"
+	OrderDeliveredUpdate = "markOrderAsDelivered"
+	CancelOrderUpdate    = "cancelOrder"
+	RequestReturnUpdate  = "requestReturn"
+	ReceiveReturnUpdate  = "receiveReturn"
+)
+
+// registerUpdates sets up the handlers operators use to move the order through
+// its lifecycle. Validators reject transitions that are illegal for the
+// current status so callers get an immediate error instead of a request that
+// is silently buffered.
+func (w *orderWorkflow) registerUpdates(ctx workflow.Context) error {
+	err := workflow.SetUpdateHandlerWithOptions(ctx, PickOrderUpdate,
+		func(ctx workflow.Context, req TransitionRequest) (OrderStatus, error) {
+			w.transition(ctx, Picked, PickOrderUpdate, req)
+			return w.status, nil
+		},
+		workflow.UpdateHandlerOptions{
+			Validator: func(req TransitionRequest) error {
+				return w.validateTransition(PickOrderUpdate, Placed)
+			},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to setup %s update handler: %w", PickOrderUpdate, err)
+	}
+
+	// shipOrder ships everything that is still outstanding in one parcel.
+	err = workflow.SetUpdateHandlerWithOptions(ctx, ShipOrderUpdate,
+		func(ctx workflow.Context, req TransitionRequest) (OrderStatus, error) {
+			w.ship(ctx, "", w.outstandingItems(), req.Actor)
+			w.transition(ctx, Shipped, ShipOrderUpdate, req)
+			return w.status, nil
+		},
+		workflow.UpdateHandlerOptions{
+			Validator: func(req TransitionRequest) error {
+				return w.validateShipping(ShipOrderUpdate)
+			},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to setup %s update handler: %w", ShipOrderUpdate, err)
+	}
+
+	err = workflow.SetUpdateHandlerWithOptions(ctx, ShipItemsUpdate,
+		func(ctx workflow.Context, req ShipmentRequest) (OrderStatus, error) {
+			w.ship(ctx, req.TrackingNumber, req.Items, req.Actor)
+			if w.fullyShipped() {
+				w.transition(ctx, Shipped, ShipItemsUpdate, TransitionRequest{Actor: req.Actor, Reason: req.Reason})
+			}
+			return w.status, nil
+		},
+		workflow.UpdateHandlerOptions{
+			Validator: func(req ShipmentRequest) error {
+				if err := w.validateShipping(ShipItemsUpdate); err != nil {
+					return err
+				}
+				return w.validateShipment(req)
+			},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to setup %s update handler: %w", ShipItemsUpdate, err)
+	}
+
+	err = workflow.SetUpdateHandlerWithOptions(ctx, OrderDeliveredUpdate,
+		func(ctx workflow.Context, req TransitionRequest) (OrderStatus, error) {
+			w.transition(ctx, Completed, OrderDeliveredUpdate, req)
+			return w.status, nil
+		},
+		workflow.UpdateHandlerOptions{
+			Validator: func(req TransitionRequest) error {
+				return w.validateTransition(OrderDeliveredUpdate, Shipped)
+			},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to setup %s update handler: %w", OrderDeliveredUpdate, err)
+	}
+
+	err = workflow.SetUpdateHandlerWithOptions(ctx, CancelOrderUpdate,
+		func(ctx workflow.Context, req TransitionRequest) (OrderStatus, error) {
+			w.recordCancellation(CancelOrderUpdate, req)
+
+			// Report the status once the workflow has finished cleaning up.
+			err := workflow.Await(ctx, func() bool {
+				return w.status.final()
+			})
+			return w.status, err
+		},
+		workflow.UpdateHandlerOptions{
+			Validator: func(req TransitionRequest) error {
+				if req.Actor == "" {
+					return fmt.Errorf("cannot %s: actor is required", CancelOrderUpdate)
+				}
+				if len(w.shipments) > 0 {
+					return fmt.Errorf("cannot %s: order has been partially shipped", CancelOrderUpdate)
+				}
+				return w.validateTransition(CancelOrderUpdate, Backordered, OnHold, Placed, Picked)
+			},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to setup %s update handler: %w", CancelOrderUpdate, err)
+	}
+
+	err = workflow.SetUpdateHandlerWithOptions(ctx, RequestReturnUpdate,
+		func(ctx workflow.Context, req ReturnRequest) (OrderStatus, error) {
+			w.returned = &Return{
+				Items:        req.Items,
+				Reason:       req.Reason,
+				RequestedAt:  workflow.Now(ctx),
+				RefundAmount: w.refundAmount(req.Items),
+			}
+			w.transition(ctx, ReturnRequested, RequestReturnUpdate, TransitionRequest{Actor: req.Actor, Reason: req.Reason})
+			return w.status, nil
+		},
+		workflow.UpdateHandlerOptions{
+			Validator: w.validateReturn,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to setup %s update handler: %w", RequestReturnUpdate, err)
+	}
+
+	err = workflow.SetUpdateHandlerWithOptions(ctx, ReceiveReturnUpdate,
+		func(ctx workflow.Context, req TransitionRequest) (OrderStatus, error) {
+			receivedAt := workflow.Now(ctx)
+			w.returned.ReceivedAt = &receivedAt
+			w.returnReceivedBy = req
+
+			// Report the status once the items have been restocked and
+			// refunded, or the failure if they could not be.
+			err := workflow.Await(ctx, func() bool {
+				return w.returnSettled
+			})
+			if err != nil {
+				return w.status, err
+			}
+			return w.status, w.returnErr
+		},
+		workflow.UpdateHandlerOptions{
+			Validator: func(req TransitionRequest) error {
+				if err := w.validateTransition(ReceiveReturnUpdate, ReturnRequested); err != nil {
+					return err
+				}
+				if w.returned.ReceivedAt != nil {
+					return fmt.Errorf("cannot %s: return has already been received", ReceiveReturnUpdate)
+				}
+				return nil
+			},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to setup %s update handler: %w", ReceiveReturnUpdate, err)
+	}
+
+	return nil
+}
+
+// validateShipping rejects a shipment unless the order has been picked and
+// processed.
+func (w *orderWorkflow) validateShipping(update string) error {
+	if err := w.validateTransition(update, Picked); err != nil {
+		return err
+	}
+	if !w.processed {
+		return fmt.Errorf("cannot %s: order is still being processed", update)
+	}
+	return nil
+}
+
+// validateTransition rejects an update unless the order is in one of the
+// given statuses and has not already been cancelled.
+func (w *orderWorkflow) validateTransition(update string, from ...OrderStatus) error {
+	if w.cancellation != nil {
+		return fmt.Errorf("cannot %s: order has been cancelled", update)
+	}
+	for _, status := range from {
+		if w.status == status {
+			return nil
+		}
+	}
+	if w.status == "" {
+		return fmt.Errorf("cannot %s: order has not been placed", update)
+	}
+	return fmt.Errorf("cannot %s: order is %s", update, w.status)
+}
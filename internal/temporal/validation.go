@@ -0,0 +1,173 @@
+package temporal
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.temporal.io/sdk/temporal"
+)
+
+// MaxLineItems is the most line items an order can have.
+const MaxLineItems = 100
+
+// FieldViolation is a rule an order breaks. InvalidOrder errors carry the
+// violations of an order as details.
+type FieldViolation struct {
+	// Field is the JSON path of the field, e.g. "line_items[0].quantity".
+	Field string `json:"field"`
+	// Rule is the validation tag the field fails, e.g. "gt".
+	Rule string `json:"rule"`
+	// Param is the parameter of the rule, if any, e.g. "0".
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+func (v FieldViolation) String() string {
+	return v.Field + " " + v.Message
+}
+
+// orderValidator checks the struct tags of Order. It is safe for concurrent
+// use.
+var orderValidator = newOrderValidator()
+
+func newOrderValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Name fields as they appear in the order's JSON.
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	must(v.RegisterValidation("nonnil_uuid", func(fl validator.FieldLevel) bool {
+		id, ok := fl.Field().Interface().(uuid.UUID)
+		return ok && id != uuid.Nil
+	}))
+	must(v.RegisterValidation("positive_decimal", func(fl validator.FieldLevel) bool {
+		d, ok := fl.Field().Interface().(decimal.Decimal)
+		return ok && d.IsPositive()
+	}))
+
+	// Rules across line items run at the order level, so that the rules of
+	// each item are checked even when they fail.
+	v.RegisterStructValidation(func(sl validator.StructLevel) {
+		order := sl.Current().Interface().(Order)
+		if len(order.LineItems) > MaxLineItems {
+			sl.ReportError(order.LineItems, "line_items", "LineItems", "max_line_items", strconv.Itoa(MaxLineItems))
+		}
+
+		seen := make(map[uuid.UUID]bool, len(order.LineItems))
+		for i, item := range order.LineItems {
+			if seen[item.ProductID] && item.ProductID != uuid.Nil {
+				sl.ReportError(item.ProductID, fmt.Sprintf("line_items[%d].product_id", i), "ProductID", "unique_products", "")
+			}
+			seen[item.ProductID] = true
+
+			// Line items are priced in the order's currency.
+			if item.Currency != "" && item.Currency != order.Currency {
+				sl.ReportError(item.Currency, fmt.Sprintf("line_items[%d].currency", i), "Currency", "order_currency", order.Currency)
+			}
+		}
+
+		// The totals the customer was shown must at least add up; whether
+		// they match what the order is charged is only known once it is
+		// priced and taxed.
+		if totals := order.Totals; totals != nil {
+			if totals.Currency != order.Currency {
+				sl.ReportError(totals.Currency, "totals.currency", "Currency", "order_currency", order.Currency)
+			}
+			sum := totals.Subtotal.Sub(totals.Discount).Add(totals.Tax).Add(totals.Shipping)
+			if !totals.Total.Equal(sum) {
+				sl.ReportError(totals.Total, "totals.total", "Total", "totals_sum", sum.String())
+			}
+		}
+	}, Order{})
+
+	return v
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// orderViolations returns every rule order breaks, both those in the struct
+// tags of Order and those checked across its line items.
+func orderViolations(order Order) []FieldViolation {
+	var errs validator.ValidationErrors
+	if !errors.As(orderValidator.Struct(order), &errs) {
+		return nil
+	}
+
+	violations := make([]FieldViolation, 0, len(errs))
+	for _, fe := range errs {
+		violations = append(violations, FieldViolation{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: violationMessage(fe),
+		})
+	}
+	return violations
+}
+
+// fieldPath returns the JSON path of the field in error, without the name of
+// the order itself.
+func fieldPath(fe validator.FieldError) string {
+	_, path, _ := strings.Cut(fe.Namespace(), ".")
+	return path
+}
+
+func violationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "nonnil_uuid":
+		return "must be a non-nil UUID"
+	case "positive_decimal":
+		return "must be positive"
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "min":
+		return fmt.Sprintf("must have at least %s items", fe.Param())
+	case "max_line_items":
+		return fmt.Sprintf("must have at most %s items", fe.Param())
+	case "unique_products":
+		return "must not repeat a product of an earlier line item"
+	case "iso4217":
+		return "must be an ISO 4217 currency code"
+	case "iso3166_1_alpha2":
+		return "must be an ISO 3166-1 alpha-2 country code"
+	case "order_currency":
+		return "must match the order currency " + fe.Param()
+	case "totals_sum":
+		return "must be the subtotal less the discount plus tax and shipping, " + fe.Param()
+	default:
+		return "fails the " + fe.Tag() + " rule"
+	}
+}
+
+// invalidOrder returns a non-retryable InvalidOrder error listing violations,
+// which it carries as details.
+func invalidOrder(violations []FieldViolation) error {
+	messages := make([]string, 0, len(violations))
+	for _, v := range violations {
+		messages = append(messages, v.String())
+	}
+	return temporal.NewNonRetryableApplicationError(
+		"invalid order: "+strings.Join(messages, "; "),
+		InvalidOrderErrorType,
+		nil,
+		violations,
+	)
+}
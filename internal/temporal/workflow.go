@@ -30,81 +30,381 @@ var (
 	}
 )
 
-// Define signals.
-const (
-	pickOrderSignal      = "pickOrder"
-	shipOrderSignal      = "shipOrder"
-	orderDeliveredSignal = "markOrderAsDelivered"
-	cancelOrderSignal    = "cancelOrder"
-)
+// OrderFailedErrorType is the application error type returned when an order
+// cannot be completed. The error details carry the workflow Result, including
+// the outcome of compensating any steps that had already completed.
+const OrderFailedErrorType = "OrderFailed"
+
+// PaymentCaptureFailedErrorType is the application error type returned when
+// the payment for a shipped order cannot be captured. The order keeps its
+// status, stock and record, as the goods have already left the warehouse; only
+// the authorization is voided and the payment needs manual follow-up. The
+// error details carry the workflow Result.
+const PaymentCaptureFailedErrorType = "PaymentCaptureFailed"
+
+// ReturnFailedErrorType is the application error type returned when returned
+// items cannot be restocked or refunded. The order moves to RETURN_FAILED and
+// the return needs manual follow-up. The workflow's error details carry the
+// workflow Result; the receiveReturn update fails with the same type.
+const ReturnFailedErrorType = "ReturnFailed"
+
+// defaultReservationTTL is how long stock is held for an order that has not
+// been picked yet when Params does not specify a TTL.
+const defaultReservationTTL = 72 * time.Hour
 
 type Params struct {
 	Order Order
+	// ReservationTTL is how long the inventory service holds stock for the
+	// order before it is committed. Defaults to defaultReservationTTL.
+	ReservationTTL time.Duration
+	// SLA sets per-stage deadlines for the order. No deadlines are enforced
+	// by default.
+	SLA SLA
+	// ReturnWindow is how long after delivery a return can be requested. The
+	// workflow completes on delivery if it is zero.
+	ReturnWindow time.Duration
+	// Backorder sets what happens if line items are out of stock. The order
+	// fails by default.
+	Backorder BackorderOptions
+	// FraudReviewTimeout is how long an order held for fraud review waits
+	// for a reviewer before it is rejected. Defaults to
+	// defaultFraudReviewTimeout.
+	FraudReviewTimeout time.Duration
 }
 
-func ProccessOrder(ctx workflow.Context, in Params) (OrderStatus, error) {
-	logger := workflow.GetLogger(ctx)
+// TransitionRequest is the argument of every order update, recording who made
+// the change and why. An actor is required to cancel an order.
+type TransitionRequest struct {
+	Actor  string `json:"actor"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Cancellation records who cancelled an order, why, and the status the order
+// was in at the time.
+type Cancellation struct {
+	Actor   string      `json:"actor"`
+	Reason  string      `json:"reason,omitempty"`
+	Stage   OrderStatus `json:"stage"`
+	Trigger string      `json:"trigger"`
+}
+
+// Result is returned by ProccessOrder once the order reaches a final status.
+type Result struct {
+	Status       OrderStatus         `json:"status"`
+	Cancellation *Cancellation       `json:"cancellation,omitempty"`
+	Compensation *CompensationResult `json:"compensation,omitempty"`
+	Return       *Return             `json:"return,omitempty"`
+	// Backorder is the order started for out of stock line items if the
+	// order was split.
+	Backorder *Backorder `json:"backorder,omitempty"`
+}
+
+// orderWorkflow holds the state of a single ProccessOrder execution.
+type orderWorkflow struct {
+	params     Params
+	status     OrderStatus
+	processed  bool
+	pricing    *PriceBreakdown
+	tax        *TaxBreakdown
+	fraud      *FraudScreening
+	processing *ProcessingResult
+	payment    *PaymentAuthorization
+	// allocations is the warehouse each line item is fulfilled from. It is
+	// empty unless the worker routes orders to warehouses.
+	allocations []Allocation
+	fulfilment  []LineFulfilment
+	shipments   []Shipment
+	history     []StatusTransition
+	saga        compensations
+	backorder   *Backorder
+	// backorderDeadline is when a backordered order stops waiting for
+	// stock, set once it is first backordered.
+	backorderDeadline time.Time
+	// restocked is set by a RestockSignal and cleared once a backordered
+	// order has checked inventory again.
+	restocked bool
 
-	var orderStatus OrderStatus
+	cancellation *Cancellation
 
-	err := workflow.SetQueryHandler(ctx, "GetOrderStatus", func() (OrderStatus, error) {
-		return orderStatus, nil
+	// Return window state, only used once the order has been delivered.
+	returnWindowOpen bool
+	returned         *Return
+	returnReceivedBy TransitionRequest
+	returnSettled    bool
+	// returnErr is why the return could not be settled, if it failed.
+	returnErr error
+}
+
+func ProccessOrder(ctx workflow.Context, in Params) (Result, error) {
+	w := &orderWorkflow{
+		params:     in,
+		fulfilment: newFulfilment(in.Order.LineItems),
+	}
+
+	err := workflow.SetQueryHandler(ctx, OrderStatusQuery, func() (OrderStatusView, error) {
+		return w.statusView(workflow.Now(ctx)), nil
 	})
 	if err != nil {
-		return orderStatus, fmt.Errorf("failed to setup query handler: %w", err)
+		return Result{Status: w.status}, fmt.Errorf("failed to setup query handler: %w", err)
+	}
+
+	if err := w.registerUpdates(ctx); err != nil {
+		return Result{Status: w.status}, err
 	}
+	w.receiveRestocks(ctx)
+
+	result, err := w.run(ctx)
+	result.Backorder = w.backorder
 
-	// Validate order and items.
+	// Let in-flight updates observe the final status before completing.
+	drainCtx, _ := workflow.NewDisconnectedContext(ctx)
+	_ = workflow.Await(drainCtx, func() bool {
+		return workflow.AllHandlersFinished(drainCtx)
+	})
+
+	return result, err
+}
+
+func (w *orderWorkflow) run(ctx workflow.Context) (Result, error) {
+	logger := workflow.GetLogger(ctx)
+
+	// Validate order and items, waiting for stock or splitting off the items
+	// that are out of stock if the backorder policy allows it.
 	ctx = workflow.WithActivityOptions(ctx, validateActivityOptions)
 
-	var orderActivities *OrderActivities
-	err = workflow.ExecuteActivity(ctx, orderActivities.Validate, in.Order).Get(ctx, nil)
+	err := w.validate(ctx)
+	if w.cancellation != nil {
+		return w.cancel(ctx)
+	}
 	if err != nil {
-		orderStatus = UnableToComplete
-		return orderStatus, err
+		return w.fail(ctx, err)
 	}
-	orderStatus = Placed
 
-	// Wait for order picked or order cancelled signals.
-	pickOrderCh := workflow.GetSignalChannel(ctx, pickOrderSignal)
-	cancelOrderCh := workflow.GetSignalChannel(ctx, cancelOrderSignal)
+	// Place the order. If its stock ran out after it was validated, it is
+	// backordered as the policy says, once its authorization and any partial
+	// reservation are undone, and placed again.
+	for {
+		err = w.place(ctx)
+		if w.cancellation != nil {
+			return w.cancel(ctx)
+		}
+		if err == nil {
+			break
+		}
+		if !w.backordersShortfall(err) {
+			return w.fail(ctx, err)
+		}
 
-	selector := workflow.NewSelector(ctx)
-	selector.AddReceive(pickOrderCh, func(c workflow.ReceiveChannel, more bool) {
-		c.Receive(ctx, nil)
-		now := workflow.Now(ctx)
-		logger.Info("Order picked at %s", now.Format("2006-01-02 15:04:05"))
-		orderStatus = Picked
-	})
-	selector.AddReceive(cancelOrderCh, func(c workflow.ReceiveChannel, more bool) {
-		c.Receive(ctx, nil)
-		orderStatus = Cancelled
-	})
+		logger.Warn("Inventory ran out before it was reserved", "error", err)
+		if outcome := w.saga.compensate(ctx); !outcome.Succeeded() {
+			w.transition(ctx, UnableToComplete, failureTrigger, TransitionRequest{Actor: systemActor, Reason: err.Error()})
+			return w.failed(err, outcome)
+		}
+		err = w.awaitReservableStock(ctx, err)
+		if w.cancellation != nil {
+			return w.cancel(ctx)
+		}
+		if err != nil {
+			return w.fail(ctx, err)
+		}
+	}
+
+	var orderActivities *OrderActivities
+	order := w.params.Order
+	pricing, tax := *w.pricing, *w.tax
+	w.transition(ctx, Placed, placedTrigger, TransitionRequest{Actor: systemActor})
 
-	// Blocks until signal is received.
-	selector.Select(ctx)
-	if orderStatus == Cancelled {
-		workflow.GetLogger(ctx).Warn("Received cancellation signal")
-		return orderStatus, nil
+	// Wait for the order to be picked or cancelled.
+	if !w.awaitTransition(ctx, Placed) {
+		return w.cancel(ctx)
 	}
+	now := workflow.Now(ctx)
+	logger.Info("Order picked", "at", now.Format("2006-01-02 15:04:05"))
 
-	// Process order.
+	// Process order. The record is voided first if the order is later undone.
 	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions)
-	var status string
-	err = workflow.ExecuteActivity(ctx, orderActivities.Process, in.Order).Get(ctx, &status)
+	w.saga.add("VoidOrder", orderActivities.VoidOrder, order.ID)
+	var processing ProcessingResult
+	err = workflow.ExecuteActivity(ctx, orderActivities.Process, order, pricing, tax).Get(ctx, &processing)
+	if err != nil {
+		return w.fail(ctx, err)
+	}
+	w.processing = &processing
+	logger.Info("Order processed", "total", processing.Total)
+
+	// Once committed the stock has left inventory, so undoing the order means
+	// putting the picked items back rather than releasing the reservation.
+	// Until the commit succeeds there is nothing to put back.
+	err = workflow.ExecuteActivity(ctx, orderActivities.CommitInventory, order.ID, w.allocations).Get(ctx, nil)
+	if err != nil {
+		return w.fail(ctx, err)
+	}
+	w.saga.remove("ReleaseInventory")
+	w.saga.add("RestockInventory", orderActivities.RestockInventory, order.ID, order.LineItems, w.allocations)
+	w.processed = true
+
+	// Wait for the order to be shipped or cancelled.
+	if !w.awaitTransition(ctx, Picked) {
+		return w.cancel(ctx)
+	}
+
+	// Take payment now that the order has shipped. A captured payment can
+	// only be refunded, not voided.
+	err = workflow.ExecuteActivity(ctx, orderActivities.CapturePayment, order.ID, w.payment.Amount).Get(ctx, nil)
+	if err != nil {
+		return w.captureFailed(ctx, err)
+	}
+	w.saga.add("RefundPayment", orderActivities.RefundPayment, order.ID, w.payment.Amount)
+
+	// Wait for the order to be marked as delivered.
+	if !w.awaitTransition(ctx, Shipped) {
+		return Result{Status: w.status}, ctx.Err()
+	}
+
+	if w.params.ReturnWindow <= 0 {
+		return Result{Status: w.status}, nil
+	}
+	return w.awaitReturn(ctx)
+}
+
+// place prices, taxes, screens and authorizes the order and reserves its
+// stock. The caller checks for a cancellation first if it returns an error.
+func (w *orderWorkflow) place(ctx workflow.Context) error {
+	var orderActivities *OrderActivities
+	order := w.params.Order
+
+	// Price the order, applying promotions and coupons, so that every later
+	// step charges the same amounts.
+	paymentCtx := workflow.WithActivityOptions(ctx, defaultActivityOptions)
+	var pricing PriceBreakdown
+	err := workflow.ExecuteActivity(paymentCtx, orderActivities.PriceOrder, order).Get(ctx, &pricing)
 	if err != nil {
-		orderStatus = UnableToComplete
-		return orderStatus, err
+		return err
 	}
-	logger.Info("Order processed", "status", status)
+	w.pricing = &pricing
 
-	// Wait for order to be shipped.
-	workflow.GetSignalChannel(ctx, shipOrderSignal).Receive(ctx, nil)
-	orderStatus = Shipped
+	// Tax the priced order for its jurisdiction.
+	var tax TaxBreakdown
+	err = workflow.ExecuteActivity(paymentCtx, orderActivities.CalculateTax, order, pricing).Get(ctx, &tax)
+	if err != nil {
+		return err
+	}
+	w.tax = &tax
 
-	// Wait for order to be marked as delivered.
-	workflow.GetSignalChannel(ctx, orderDeliveredSignal).Receive(ctx, nil)
-	orderStatus = Completed
+	// Screen the order for fraud before funds or stock are held for it,
+	// holding it for review if the scorer asks for it. An order placed again
+	// after a shortfall has no more items than when it was screened, so it is
+	// not screened again.
+	if w.fraud == nil {
+		if err := w.screen(paymentCtx, order, pricing, tax); err != nil {
+			return err
+		}
+	}
+	// An order cancelled while held for review has nothing held to undo.
+	if w.cancellation != nil {
+		return nil
+	}
+
+	// Authorize payment for the order. As with the reservation below, the void
+	// is registered first in case the gateway placed a hold before failing.
+	w.saga.add("VoidPayment", orderActivities.VoidPayment, order.ID)
+	var payment PaymentAuthorization
+	err = workflow.ExecuteActivity(paymentCtx, orderActivities.AuthorizePayment, order, pricing, tax).Get(ctx, &payment)
+	if err != nil {
+		return err
+	}
+	w.payment = &payment
+
+	// Hold stock for the order. The release is registered first because a
+	// reservation may have been made even if the activity reports a failure.
+	reservationTTL := w.params.ReservationTTL
+	if reservationTTL <= 0 {
+		reservationTTL = defaultReservationTTL
+	}
+	w.saga.add("ReleaseInventory", orderActivities.ReleaseInventory, order.ID, w.allocations)
+	err = workflow.ExecuteActivity(ctx, orderActivities.ReserveInventory, order, w.allocations, reservationTTL).Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
 
-	return orderStatus, nil
+// awaitTransition blocks until an update moves the order out of the given
+// status and reports true, or until the order is cancelled by update, SLA
+// breach or workflow cancellation and reports false.
+func (w *orderWorkflow) awaitTransition(ctx workflow.Context, from OrderStatus) bool {
+	proceed, err := w.awaitWithSLA(ctx, from, func() bool {
+		return w.status != from || w.cancellation != nil
+	})
+	if err != nil {
+		if w.cancellation == nil {
+			w.recordCancellation(workflowCancelledTrigger, TransitionRequest{Actor: systemActor, Reason: "workflow cancelled"})
+		}
+		return false
+	}
+
+	return proceed && w.cancellation == nil
+}
+
+func (w *orderWorkflow) recordCancellation(trigger string, req TransitionRequest) {
+	w.cancellation = &Cancellation{
+		Actor:   req.Actor,
+		Reason:  req.Reason,
+		Stage:   w.status,
+		Trigger: trigger,
+	}
+}
+
+// fail undoes every completed step and reports the outcome alongside the
+// original error. An order whose workflow was cancelled while an activity was
+// running is treated as cancelled rather than failed.
+func (w *orderWorkflow) fail(ctx workflow.Context, cause error) (Result, error) {
+	if ctx.Err() != nil && temporal.IsCanceledError(cause) {
+		w.recordCancellation(workflowCancelledTrigger, TransitionRequest{Actor: systemActor, Reason: "workflow cancelled"})
+		return w.cancel(ctx)
+	}
+
+	w.transition(ctx, UnableToComplete, failureTrigger, TransitionRequest{Actor: systemActor, Reason: cause.Error()})
+	return w.failed(cause, w.saga.compensate(ctx))
+}
+
+// failed reports an order that could not be completed, with the outcome of
+// undoing its completed steps.
+func (w *orderWorkflow) failed(cause error, outcome CompensationResult) (Result, error) {
+	result := Result{Status: w.status, Compensation: &outcome}
+	return result, temporal.NewApplicationErrorWithCause("unable to complete order", OrderFailedErrorType, cause, result)
+}
+
+// captureFailed voids the authorization of a shipped order whose payment
+// could not be captured. The goods have left the warehouse, so the order is not
+// undone: it stays SHIPPED, its stock and record are left as they are, and the
+// payment is reported for manual follow-up.
+func (w *orderWorkflow) captureFailed(ctx workflow.Context, cause error) (Result, error) {
+	workflow.GetLogger(ctx).Error("Unable to capture payment for shipped order", "error", cause)
+
+	var orderActivities *OrderActivities
+	w.saga = nil
+	w.saga.add("VoidPayment", orderActivities.VoidPayment, w.params.Order.ID)
+	outcome := w.saga.compensate(ctx)
+	result := Result{Status: w.status, Compensation: &outcome}
+	return result, temporal.NewApplicationErrorWithCause("unable to capture payment for shipped order", PaymentCaptureFailedErrorType, cause, result)
+}
+
+// cancel undoes every step completed before the stage the order was cancelled
+// in. The workflow is reported as cancelled to Temporal only if it was
+// cancelled through Temporal rather than by signal.
+func (w *orderWorkflow) cancel(ctx workflow.Context) (Result, error) {
+	workflow.GetLogger(ctx).Warn("Order cancelled",
+		"stage", w.cancellation.Stage,
+		"actor", w.cancellation.Actor,
+		"reason", w.cancellation.Reason,
+	)
+
+	w.transition(ctx, Cancelled, w.cancellation.Trigger, TransitionRequest{
+		Actor:  w.cancellation.Actor,
+		Reason: w.cancellation.Reason,
+	})
+	outcome := w.saga.compensate(ctx)
+	result := Result{Status: w.status, Cancellation: w.cancellation, Compensation: &outcome}
+	return result, ctx.Err()
 }
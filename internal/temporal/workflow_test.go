@@ -1,13 +1,17 @@
 package temporal_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/pulinau/demo-temporal-order-processor/internal/temporal"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
+	sdktemporal "go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/testsuite"
 )
 
@@ -33,22 +37,52 @@ func (s *WorkflowTestSuite) AfterTest(suiteName, testName string) {
 	s.env.AssertExpectations(s.T())
 }
 
+// updateWorkflow sends an update that must be accepted and complete without
+// error.
+func (s *WorkflowTestSuite) updateWorkflow(name string, args ...interface{}) {
+	s.env.UpdateWorkflow(name, uuid.NewString(), &testsuite.TestUpdateCallback{
+		OnReject: func(err error) {
+			s.Fail("update should not be rejected", "%s: %v", name, err)
+		},
+		OnAccept: func() {},
+		OnComplete: func(_ interface{}, err error) {
+			s.NoError(err, "%s update should complete", name)
+		},
+	}, args...)
+}
+
 func (s *WorkflowTestSuite) TestWorkflow_Success() {
 	// Mock activity implementations.
 
-	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil)
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	authorization := temporal.PaymentAuthorization{ID: "auth-1", Approved: true, Amount: decimal.RequireFromString("114.99")}
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(authorization, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), 72*time.Hour).Return(nil)
 
 	s.env.RegisterDelayedCallback(func() {
-		s.env.SignalWorkflow("pickOrder", nil)
+		s.updateWorkflow("pickOrder", temporal.TransitionRequest{Actor: "warehouse"})
 	}, time.Minute)
 
-	s.env.OnActivity(s.activities.Process, mock.Anything, temporal.Order{}).Return("PROCESSED", nil)
+	processing := temporal.ProcessingResult{
+		Subtotal: decimal.RequireFromString("100.00"),
+		Tax:      decimal.RequireFromString("10.00"),
+		Shipping: decimal.RequireFromString("4.99"),
+		Total:    decimal.RequireFromString("114.99"),
+	}
+	s.env.OnActivity(s.activities.Process, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(processing, nil)
+	s.env.OnActivity(s.activities.CommitInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil)
 
 	s.env.RegisterDelayedCallback(func() {
-		s.env.SignalWorkflow("shipOrder", nil)
+		s.updateWorkflow("shipOrder")
 	}, 2*time.Hour)
+	s.env.OnActivity(s.activities.CapturePayment, mock.Anything, uuid.UUID{}, mock.MatchedBy(func(amount decimal.Decimal) bool {
+		return amount.Equal(authorization.Amount)
+	})).Return(nil).Once()
 	s.env.RegisterDelayedCallback(func() {
-		s.env.SignalWorkflow("markOrderAsDelivered", nil)
+		s.updateWorkflow("markOrderAsDelivered")
 	}, 5*24*time.Hour)
 
 	// Execute workflow.
@@ -61,19 +95,159 @@ func (s *WorkflowTestSuite) TestWorkflow_Success() {
 
 	val, err := s.env.QueryWorkflow("GetOrderStatus")
 	s.Require().NoError(err, "workflow should be queryable")
-	var got temporal.OrderStatus
+	var got temporal.OrderStatusView
 	err = val.Get(&got)
-	s.Require().NoError(err, "query result should be a temporal.OrderStatus")
-	s.Equal(temporal.Completed, got, "order should be completed")
+	s.Require().NoError(err, "query result should be a temporal.OrderStatusView")
+	s.Equal(temporal.Completed, got.Status, "order should be completed")
+
+	// Assert the full history and time spent in each stage.
+
+	s.Require().Len(got.History, 4)
+	wantTransitions := []struct {
+		from, to temporal.OrderStatus
+		trigger  string
+	}{
+		{"", temporal.Placed, "inventoryReserved"},
+		{temporal.Placed, temporal.Picked, "pickOrder"},
+		{temporal.Picked, temporal.Shipped, "shipOrder"},
+		{temporal.Shipped, temporal.Completed, "markOrderAsDelivered"},
+	}
+	for i, want := range wantTransitions {
+		s.Equal(want.from, got.History[i].From)
+		s.Equal(want.to, got.History[i].To)
+		s.Equal(want.trigger, got.History[i].Trigger)
+	}
+	s.Equal("system", got.History[0].Actor)
+	s.Equal("warehouse", got.History[1].Actor)
+	s.Equal(got.History[3].At, got.Since)
+	s.Equal(time.Minute, got.StageDurations[temporal.Placed])
+	s.Equal(2*time.Hour-time.Minute, got.StageDurations[temporal.Picked])
+	s.Equal(5*24*time.Hour-2*time.Hour, got.StageDurations[temporal.Shipped])
+	s.NotContains(got.StageDurations, temporal.Completed)
+
+	s.Require().NotNil(got.Processing, "processing result should be exposed")
+	s.True(processing.Total.Equal(got.Processing.Total))
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_CarriesAllocations() {
+	// Mock activity implementations.
+
+	productA, productB := uuid.New(), uuid.New()
+	order := temporal.Order{
+		ID: uuid.New(),
+		LineItems: []temporal.LineItem{
+			{ProductID: productA, Quantity: 1, PricePerItem: decimal.RequireFromString("10.00")},
+			{ProductID: productB, Quantity: 2, PricePerItem: decimal.RequireFromString("5.00")},
+		},
+	}
+	allocations := []temporal.Allocation{
+		{ProductID: productA, Quantity: 1, Warehouse: "sydney"},
+		{ProductID: productB, Quantity: 2, Warehouse: "perth"},
+	}
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, mock.Anything).Return(allocations, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, mock.Anything).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, mock.Anything, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, mock.Anything, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, mock.Anything, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, mock.Anything, allocations, mock.Anything).Return(nil).Once()
+	s.env.OnActivity(s.activities.ReleaseInventory, mock.Anything, order.ID, allocations).Return(nil).Once()
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, order.ID).Return(nil)
+
+	var view temporal.OrderStatusView
+	s.env.RegisterDelayedCallback(func() {
+		val, err := s.env.QueryWorkflow("GetOrderStatus")
+		s.Require().NoError(err)
+		s.Require().NoError(val.Get(&view))
+	}, time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("cancelOrder", temporal.TransitionRequest{Actor: "customer"})
+	}, 2*time.Minute)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: order})
+
+	// Assert the allocation was reserved, released and exposed.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+	s.Equal(allocations, view.Allocations)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_ChargesPricedAndTaxedOrder() {
+	// Mock activity implementations.
+
+	pricing := temporal.PriceBreakdown{
+		Lines:     []temporal.LinePrice{{ProductID: uuid.New(), Quantity: 1, UnitPrice: decimal.RequireFromString("20.00"), Total: decimal.RequireFromString("20.00")}},
+		Discounts: []temporal.Discount{{Promotion: "WELCOME5", Amount: decimal.RequireFromString("5.00")}},
+		Coupons:   []string{"WELCOME5"},
+	}
+	priced := mock.MatchedBy(func(p temporal.PriceBreakdown) bool {
+		return len(p.Discounts) == 1 && p.Discounts[0].Promotion == "WELCOME5"
+	})
+	tax := temporal.TaxBreakdown{
+		Jurisdiction: "AU-NSW",
+		Lines:        []temporal.LineTax{{ProductID: pricing.Lines[0].ProductID, Taxable: decimal.RequireFromString("15.00"), Rate: decimal.RequireFromString("0.10"), Amount: decimal.RequireFromString("1.50")}},
+		Total:        decimal.RequireFromString("1.50"),
+	}
+	taxed := mock.MatchedBy(func(t temporal.TaxBreakdown) bool {
+		return t.Jurisdiction == "AU-NSW" && t.Total.Equal(decimal.RequireFromString("1.50"))
+	})
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(pricing, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, priced).Return(tax, nil).Once()
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, priced, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, priced, taxed).Return(temporal.PaymentAuthorization{Approved: true}, nil).Once()
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(nil)
+	s.env.OnActivity(s.activities.Process, mock.Anything, temporal.Order{}, priced, taxed).Return(temporal.ProcessingResult{}, nil).Once()
+	s.env.OnActivity(s.activities.CommitInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("pickOrder", temporal.TransitionRequest{Actor: "warehouse"})
+	}, time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("cancelOrder", temporal.TransitionRequest{Actor: "support"})
+	}, time.Hour)
+	s.env.OnActivity(s.activities.RestockInventory, mock.Anything, uuid.UUID{}, []temporal.LineItem(nil), []temporal.Allocation(nil)).Return(nil)
+	s.env.OnActivity(s.activities.VoidOrder, mock.Anything, uuid.UUID{}).Return(nil)
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, uuid.UUID{}).Return(nil)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: temporal.Order{}})
+
+	// Assert the pricing and tax were charged and exposed by the status query.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err, "workflow should be queryable")
+	var got temporal.OrderStatusView
+	s.Require().NoError(val.Get(&got))
+	s.Require().NotNil(got.Pricing, "pricing should be exposed")
+	s.Equal([]string{"WELCOME5"}, got.Pricing.Coupons)
+	s.Require().Len(got.Pricing.Discounts, 1)
+	s.True(got.Pricing.Discounts[0].Amount.Equal(decimal.RequireFromString("5.00")))
+	s.Require().NotNil(got.Tax, "tax should be exposed")
+	s.Equal("AU-NSW", got.Tax.Jurisdiction)
+	s.True(got.Tax.Total.Equal(decimal.RequireFromString("1.50")))
 }
 
 func (s *WorkflowTestSuite) TestWorkflow_Cancelled() {
 	// Mock activity implementations.
 
-	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil)
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(nil)
+	s.env.OnActivity(s.activities.ReleaseInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil)
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, uuid.UUID{}).Return(nil)
 
 	s.env.RegisterDelayedCallback(func() {
-		s.env.SignalWorkflow("cancelOrder", nil)
+		s.updateWorkflow("cancelOrder", temporal.TransitionRequest{Actor: "customer", Reason: "changed my mind"})
 	}, time.Minute)
 
 	// Execute workflow.
@@ -86,8 +260,1433 @@ func (s *WorkflowTestSuite) TestWorkflow_Cancelled() {
 
 	val, err := s.env.QueryWorkflow("GetOrderStatus")
 	s.Require().NoError(err, "workflow should be queryable")
-	var got temporal.OrderStatus
+	var got temporal.OrderStatusView
 	err = val.Get(&got)
-	s.Require().NoError(err, "query result should be a temporal.OrderStatus")
-	s.Equal(temporal.Cancelled, got, "order should be cancelled")
+	s.Require().NoError(err, "query result should be a temporal.OrderStatusView")
+	s.Equal(temporal.Cancelled, got.Status, "order should be cancelled")
+
+	var result temporal.Result
+	s.Require().NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(temporal.Cancelled, result.Status)
+	s.Require().NotNil(result.Compensation, "cancelled order should report compensation outcome")
+	s.True(result.Compensation.Succeeded())
+	s.Equal([]string{"ReleaseInventory", "VoidPayment"}, result.Compensation.Compensated, "reserved inventory and payment should be released")
+	s.Equal(&temporal.Cancellation{
+		Actor:   "customer",
+		Reason:  "changed my mind",
+		Stage:   temporal.Placed,
+		Trigger: "cancelOrder",
+	}, result.Cancellation)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_CancelledAfterPicked() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("pickOrder")
+	}, time.Minute)
+
+	s.env.OnActivity(s.activities.Process, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.ProcessingResult{}, nil)
+	s.env.OnActivity(s.activities.CommitInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("cancelOrder", temporal.TransitionRequest{Actor: "support", Reason: "duplicate order"})
+	}, time.Hour)
+
+	// Picked stock has been committed, so it is restocked instead of the
+	// reservation being released.
+	s.env.OnActivity(s.activities.RestockInventory, mock.Anything, uuid.UUID{}, []temporal.LineItem(nil), []temporal.Allocation(nil)).Return(nil)
+	s.env.OnActivity(s.activities.VoidOrder, mock.Anything, uuid.UUID{}).Return(nil)
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, uuid.UUID{}).Return(nil)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: temporal.Order{}})
+
+	// Assert execution, cancellation and stage-aware compensation.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+
+	var result temporal.Result
+	s.Require().NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(temporal.Cancelled, result.Status)
+	s.Equal(&temporal.Cancellation{
+		Actor:   "support",
+		Reason:  "duplicate order",
+		Stage:   temporal.Picked,
+		Trigger: "cancelOrder",
+	}, result.Cancellation)
+	s.Require().NotNil(result.Compensation)
+	s.Equal([]string{"RestockInventory", "VoidOrder", "VoidPayment"}, result.Compensation.Compensated)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_ProcessFailed() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(nil)
+	s.env.OnActivity(s.activities.ReleaseInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil)
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, uuid.UUID{}).Return(nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("pickOrder")
+	}, time.Minute)
+
+	s.env.OnActivity(s.activities.Process, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.ProcessingResult{}, errors.New("processing failed"))
+	s.env.OnActivity(s.activities.VoidOrder, mock.Anything, uuid.UUID{}).Return(nil)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: temporal.Order{}})
+
+	// Assert execution, order status and compensation outcome.
+
+	err := s.env.GetWorkflowError()
+	s.Require().Error(err)
+
+	var appErr *sdktemporal.ApplicationError
+	s.Require().ErrorAs(err, &appErr)
+	s.Equal(temporal.OrderFailedErrorType, appErr.Type())
+
+	var result temporal.Result
+	s.Require().NoError(appErr.Details(&result), "error should carry the workflow result")
+	s.Equal(temporal.UnableToComplete, result.Status)
+	s.Require().NotNil(result.Compensation)
+	s.True(result.Compensation.Succeeded())
+	s.Equal([]string{"VoidOrder", "ReleaseInventory", "VoidPayment"}, result.Compensation.Compensated, "reserved inventory and payment should be released")
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err, "workflow should be queryable")
+	var got temporal.OrderStatusView
+	err = val.Get(&got)
+	s.Require().NoError(err, "query result should be a temporal.OrderStatusView")
+	s.Equal(temporal.UnableToComplete, got.Status, "order should be unable to complete")
+	last := got.History[len(got.History)-1]
+	s.Equal("failure", last.Trigger)
+	s.Contains(last.Reason, "processing failed", "failure reason should be recorded")
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_CommitFailed() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(nil)
+	s.env.OnActivity(s.activities.ReleaseInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil)
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, uuid.UUID{}).Return(nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("pickOrder")
+	}, time.Minute)
+
+	s.env.OnActivity(s.activities.Process, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.ProcessingResult{}, nil)
+	s.env.OnActivity(s.activities.VoidOrder, mock.Anything, uuid.UUID{}).Return(nil)
+	// The reservation has expired, so no stock was deducted.
+	s.env.OnActivity(s.activities.CommitInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).
+		Return(sdktemporal.NewNonRetryableApplicationError("reservation not found", temporal.ReservationNotFoundErrorType, nil))
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: temporal.Order{}})
+
+	// Assert the uncommitted stock is released rather than restocked.
+
+	var appErr *sdktemporal.ApplicationError
+	s.Require().ErrorAs(s.env.GetWorkflowError(), &appErr)
+	s.Equal(temporal.OrderFailedErrorType, appErr.Type())
+
+	var result temporal.Result
+	s.Require().NoError(appErr.Details(&result))
+	s.Equal(temporal.UnableToComplete, result.Status)
+	s.Require().NotNil(result.Compensation)
+	s.True(result.Compensation.Succeeded())
+	s.Equal([]string{"VoidOrder", "ReleaseInventory", "VoidPayment"}, result.Compensation.Compensated, "stock that was never committed should not be restocked")
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_ReservationFailedCompensationFailed() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).
+		Return(sdktemporal.NewNonRetryableApplicationError("insufficient inventory to reserve order", "reservation", nil))
+	s.env.OnActivity(s.activities.ReleaseInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).
+		Return(sdktemporal.NewNonRetryableApplicationError("release failed", "test", nil))
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, uuid.UUID{}).Return(nil)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: temporal.Order{}})
+
+	// Assert failed compensation is reported.
+
+	var appErr *sdktemporal.ApplicationError
+	s.Require().ErrorAs(s.env.GetWorkflowError(), &appErr)
+
+	var result temporal.Result
+	s.Require().NoError(appErr.Details(&result))
+	s.Equal(temporal.UnableToComplete, result.Status)
+	s.Require().NotNil(result.Compensation)
+	s.False(result.Compensation.Succeeded())
+	s.Equal([]string{"VoidPayment"}, result.Compensation.Compensated, "compensation should continue past a failed step")
+	s.Require().Len(result.Compensation.Failed, 1)
+	s.Equal("ReleaseInventory", result.Compensation.Failed[0].Step)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_IllegalTransitionsRejected() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(nil)
+	s.env.OnActivity(s.activities.ReleaseInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil)
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, uuid.UUID{}).Return(nil)
+
+	rejected := map[string]error{}
+	reject := func(name string, args ...interface{}) {
+		s.env.UpdateWorkflow(name, uuid.NewString(), &testsuite.TestUpdateCallback{
+			OnReject: func(err error) {
+				rejected[name] = err
+			},
+			OnAccept: func() {
+				s.Fail("update should be rejected", name)
+			},
+			OnComplete: func(interface{}, error) {},
+		}, args...)
+	}
+
+	s.env.RegisterDelayedCallback(func() {
+		reject("shipOrder")
+		reject("markOrderAsDelivered")
+		reject("cancelOrder", temporal.TransitionRequest{})
+	}, time.Minute)
+
+	var cancelStatus temporal.OrderStatus
+	s.env.RegisterDelayedCallback(func() {
+		s.env.UpdateWorkflow("cancelOrder", uuid.NewString(), &testsuite.TestUpdateCallback{
+			OnReject: func(err error) {
+				s.Fail("update should not be rejected", err)
+			},
+			OnAccept: func() {},
+			OnComplete: func(v interface{}, err error) {
+				s.Require().NoError(err)
+				cancelStatus = v.(temporal.OrderStatus)
+			},
+		}, temporal.TransitionRequest{Actor: "support"})
+	}, time.Hour)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: temporal.Order{}})
+
+	// Assert rejected updates and that the accepted cancel returned the new status.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+	s.ErrorContains(rejected["shipOrder"], "cannot shipOrder: order is PLACED")
+	s.ErrorContains(rejected["markOrderAsDelivered"], "cannot markOrderAsDelivered: order is PLACED")
+	s.ErrorContains(rejected["cancelOrder"], "actor is required")
+	s.Equal(temporal.Cancelled, cancelStatus, "cancel update should return the new status")
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_SLABreachAutoCancelled() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(nil)
+	s.env.OnActivity(s.activities.Escalate, mock.Anything, mock.MatchedBy(func(e temporal.Escalation) bool {
+		return e.Stage == temporal.Placed && e.Deadline == 24*time.Hour && e.AutoCancel
+	})).Return(nil)
+	s.env.OnActivity(s.activities.ReleaseInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil)
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, uuid.UUID{}).Return(nil)
+
+	// Execute workflow without ever picking the order.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{
+		Order: temporal.Order{},
+		SLA: temporal.SLA{
+			PickWithin: 24 * time.Hour,
+			AutoCancel: true,
+		},
+	})
+
+	// Assert the order was escalated and cancelled by the system.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+
+	var result temporal.Result
+	s.Require().NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(temporal.Cancelled, result.Status)
+	s.Require().NotNil(result.Cancellation)
+	s.Equal("system", result.Cancellation.Actor)
+	s.Equal("slaBreach", result.Cancellation.Trigger)
+	s.Equal(temporal.Placed, result.Cancellation.Stage)
+	s.Contains(result.Cancellation.Reason, "SLA breached")
+	s.Equal([]string{"ReleaseInventory", "VoidPayment"}, result.Compensation.Compensated)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_SLABreachEscalatedOnly() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("pickOrder")
+	}, time.Minute)
+
+	s.env.OnActivity(s.activities.Process, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.ProcessingResult{}, nil)
+	s.env.OnActivity(s.activities.CommitInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil)
+
+	// Shipping is late, but the order is only escalated.
+	s.env.OnActivity(s.activities.Escalate, mock.Anything, mock.MatchedBy(func(e temporal.Escalation) bool {
+		return e.Stage == temporal.Picked && e.Deadline == time.Hour && !e.AutoCancel
+	})).Return(nil).Once()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("shipOrder")
+	}, 2*time.Hour)
+	s.env.OnActivity(s.activities.CapturePayment, mock.Anything, uuid.UUID{}, mock.Anything).Return(nil)
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("markOrderAsDelivered")
+	}, 3*time.Hour)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{
+		Order: temporal.Order{},
+		SLA: temporal.SLA{
+			ShipWithin:    time.Hour,
+			DeliverWithin: 10 * 24 * time.Hour,
+		},
+	})
+
+	// Assert the order still completed.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+
+	var result temporal.Result
+	s.Require().NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(temporal.Completed, result.Status)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_PaymentDeclined() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).
+		Return(temporal.PaymentAuthorization{}, sdktemporal.NewNonRetryableApplicationError("payment declined", "payment", nil))
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, uuid.UUID{}).Return(nil)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: temporal.Order{}})
+
+	// Assert the order failed before inventory was reserved.
+
+	var appErr *sdktemporal.ApplicationError
+	s.Require().ErrorAs(s.env.GetWorkflowError(), &appErr)
+	s.Equal(temporal.OrderFailedErrorType, appErr.Type())
+
+	var result temporal.Result
+	s.Require().NoError(appErr.Details(&result))
+	s.Equal(temporal.UnableToComplete, result.Status)
+	s.Require().NotNil(result.Compensation)
+	s.Equal([]string{"VoidPayment"}, result.Compensation.Compensated)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_CaptureFailed() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("pickOrder")
+	}, time.Minute)
+
+	s.env.OnActivity(s.activities.Process, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.ProcessingResult{}, nil)
+	s.env.OnActivity(s.activities.CommitInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("shipOrder")
+	}, time.Hour)
+
+	s.env.OnActivity(s.activities.CapturePayment, mock.Anything, uuid.UUID{}, mock.Anything).
+		Return(sdktemporal.NewNonRetryableApplicationError("authorization not found", "test", nil))
+	// The goods have shipped, so only the authorization is voided: nothing
+	// is restocked, released or voided in the order store.
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, uuid.UUID{}).Return(nil).Once()
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: temporal.Order{}})
+
+	// Assert the payment failed without unwinding the shipped order.
+
+	var appErr *sdktemporal.ApplicationError
+	s.Require().ErrorAs(s.env.GetWorkflowError(), &appErr)
+	s.Equal(temporal.PaymentCaptureFailedErrorType, appErr.Type())
+
+	var result temporal.Result
+	s.Require().NoError(appErr.Details(&result))
+	s.Equal(temporal.Shipped, result.Status, "shipped order should keep its status")
+	s.Require().NotNil(result.Compensation)
+	s.Equal([]string{"VoidPayment"}, result.Compensation.Compensated)
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err, "workflow should be queryable")
+	var got temporal.OrderStatusView
+	s.Require().NoError(val.Get(&got))
+	s.Equal(temporal.Shipped, got.Status)
+	s.NotEqual(temporal.UnableToComplete, got.History[len(got.History)-1].To, "shipped order should not be failed")
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_PartialShipments() {
+	productA := uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721")
+	productB := uuid.MustParse("5a1c1f6e-0c43-4c1c-9a55-1b7f0f5c2c1e")
+	order := temporal.Order{
+		ID: uuid.MustParse("8c727b70-cfcb-4674-8bcd-78e66e32f723"),
+		LineItems: []temporal.LineItem{
+			{ProductID: productA, Quantity: 3},
+			{ProductID: productB, Quantity: 1},
+		},
+	}
+
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, mock.Anything).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, mock.Anything).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, mock.Anything, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, mock.Anything, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, mock.Anything, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity(s.activities.Process, mock.Anything, mock.Anything, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.ProcessingResult{}, nil)
+	s.env.OnActivity(s.activities.CommitInventory, mock.Anything, order.ID, []temporal.Allocation(nil)).Return(nil)
+	s.env.OnActivity(s.activities.CapturePayment, mock.Anything, order.ID, mock.Anything).Return(nil).Once()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("pickOrder")
+	}, time.Minute)
+
+	// The first parcel leaves some of product A behind.
+	var afterFirstParcel temporal.OrderStatusView
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("shipItems", temporal.ShipmentRequest{
+			Actor:          "warehouse",
+			TrackingNumber: "TRACK-1",
+			Items: []temporal.ShipmentItem{
+				{ProductID: productA, Quantity: 2},
+				{ProductID: productB, Quantity: 1},
+			},
+		})
+	}, time.Hour)
+	s.env.RegisterDelayedCallback(func() {
+		val, err := s.env.QueryWorkflow("GetOrderStatus")
+		s.Require().NoError(err)
+		s.Require().NoError(val.Get(&afterFirstParcel))
+	}, 90*time.Minute)
+
+	// Shipping more than is outstanding and cancelling a partially shipped
+	// order are both rejected.
+	rejected := map[string]error{}
+	s.env.RegisterDelayedCallback(func() {
+		reject := func(name string, arg interface{}) {
+			s.env.UpdateWorkflow(name, uuid.NewString(), &testsuite.TestUpdateCallback{
+				OnReject:   func(err error) { rejected[name] = err },
+				OnAccept:   func() { s.Fail("update should be rejected", name) },
+				OnComplete: func(interface{}, error) {},
+			}, arg)
+		}
+		reject("shipItems", temporal.ShipmentRequest{
+			TrackingNumber: "TRACK-2",
+			Items:          []temporal.ShipmentItem{{ProductID: productB, Quantity: 1}},
+		})
+		reject("cancelOrder", temporal.TransitionRequest{Actor: "support"})
+	}, 2*time.Hour)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("shipItems", temporal.ShipmentRequest{
+			Actor:          "warehouse",
+			TrackingNumber: "TRACK-2",
+			Items:          []temporal.ShipmentItem{{ProductID: productA, Quantity: 1}},
+		})
+	}, 3*time.Hour)
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("markOrderAsDelivered")
+	}, 24*time.Hour)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: order})
+
+	// Assert the order only shipped once every item had left.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+
+	s.Equal(temporal.Picked, afterFirstParcel.Status, "partially shipped order should stay picked")
+	s.Equal([]temporal.LineFulfilment{
+		{ProductID: productA, Ordered: 3, Shipped: 2, Outstanding: 1},
+		{ProductID: productB, Ordered: 1, Shipped: 1, Outstanding: 0},
+	}, afterFirstParcel.Fulfilment)
+
+	s.ErrorContains(rejected["shipItems"], "only 0 of product "+productB.String()+" left to ship")
+	s.ErrorContains(rejected["cancelOrder"], "order has been partially shipped")
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err)
+	var got temporal.OrderStatusView
+	s.Require().NoError(val.Get(&got))
+	s.Equal(temporal.Completed, got.Status)
+	s.Require().Len(got.Shipments, 2)
+	s.Equal("TRACK-2", got.Shipments[1].TrackingNumber)
+	for _, line := range got.Fulfilment {
+		s.Zero(line.Outstanding)
+	}
+
+	shipped := got.History[2]
+	s.Equal(temporal.Shipped, shipped.To)
+	s.Equal("shipItems", shipped.Trigger)
+	s.Equal(3*time.Hour, shipped.At.Sub(got.History[0].At))
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_ReturnRefunded() {
+	product := uuid.MustParse("ba320a5d-62ed-46d0-b491-084514598721")
+	order := temporal.Order{
+		ID:        uuid.MustParse("8c727b70-cfcb-4674-8bcd-78e66e32f723"),
+		LineItems: []temporal.LineItem{{ProductID: product, Quantity: 2, PricePerItem: decimal.RequireFromString("10.00")}},
+	}
+	processing := temporal.ProcessingResult{
+		OrderID:  order.ID,
+		Lines:    []temporal.LineTotal{{ProductID: product, Quantity: 2, PricePerItem: decimal.RequireFromString("10.00"), Total: decimal.RequireFromString("20.00"), Tax: decimal.RequireFromString("2.00")}},
+		Subtotal: decimal.RequireFromString("20.00"),
+		Tax:      decimal.RequireFromString("2.00"),
+		Shipping: decimal.RequireFromString("4.99"),
+		Total:    decimal.RequireFromString("26.99"),
+	}
+
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, mock.Anything).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, mock.Anything).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, mock.Anything, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, mock.Anything, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, mock.Anything, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true, Amount: processing.Total}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity(s.activities.Process, mock.Anything, mock.Anything, temporal.PriceBreakdown{}, mock.Anything).Return(processing, nil)
+	s.env.OnActivity(s.activities.CommitInventory, mock.Anything, order.ID, []temporal.Allocation(nil)).Return(nil)
+	s.env.OnActivity(s.activities.CapturePayment, mock.Anything, order.ID, mock.Anything).Return(nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("pickOrder")
+	}, time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("shipOrder")
+	}, time.Hour)
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("markOrderAsDelivered")
+	}, 2*time.Hour)
+
+	// One of the two items is returned; returning more than was shipped is
+	// rejected.
+	var overReturnErr error
+	s.env.RegisterDelayedCallback(func() {
+		s.env.UpdateWorkflow("requestReturn", uuid.NewString(), &testsuite.TestUpdateCallback{
+			OnReject:   func(err error) { overReturnErr = err },
+			OnAccept:   func() { s.Fail("update should be rejected") },
+			OnComplete: func(interface{}, error) {},
+		}, temporal.ReturnRequest{Actor: "customer", Items: []temporal.ShipmentItem{{ProductID: product, Quantity: 3}}})
+
+		s.updateWorkflow("requestReturn", temporal.ReturnRequest{
+			Actor:  "customer",
+			Reason: "damaged",
+			Items:  []temporal.ShipmentItem{{ProductID: product, Quantity: 1}},
+		})
+	}, 3*24*time.Hour)
+
+	s.env.OnActivity(s.activities.RestockInventory, mock.Anything, order.ID, mock.MatchedBy(func(items []temporal.LineItem) bool {
+		return len(items) == 1 && items[0].ProductID == product && items[0].Quantity == 1
+	}), []temporal.Allocation(nil)).Return(nil).Once()
+	s.env.OnActivity(s.activities.RefundPayment, mock.Anything, order.ID, mock.MatchedBy(func(amount decimal.Decimal) bool {
+		return amount.Equal(decimal.RequireFromString("11.00"))
+	})).Return(nil).Once()
+
+	var receiveStatus temporal.OrderStatus
+	s.env.RegisterDelayedCallback(func() {
+		s.env.UpdateWorkflow("receiveReturn", uuid.NewString(), &testsuite.TestUpdateCallback{
+			OnReject: func(err error) { s.Fail("update should not be rejected", err) },
+			OnAccept: func() {},
+			OnComplete: func(v interface{}, err error) {
+				s.Require().NoError(err)
+				receiveStatus = v.(temporal.OrderStatus)
+			},
+		}, temporal.TransitionRequest{Actor: "warehouse"})
+	}, 5*24*time.Hour)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: order, ReturnWindow: 14 * 24 * time.Hour})
+
+	// Assert the returned item was restocked and refunded with its share of tax.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+	s.ErrorContains(overReturnErr, "only 2 of product "+product.String()+" were shipped")
+	s.Equal(temporal.Refunded, receiveStatus, "receive update should return the new status")
+
+	var result temporal.Result
+	s.Require().NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(temporal.Refunded, result.Status)
+	s.Require().NotNil(result.Return)
+	s.Equal("damaged", result.Return.Reason)
+	s.True(decimal.RequireFromString("11.00").Equal(result.Return.RefundAmount))
+	s.Require().NotNil(result.Return.ReceivedAt)
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err)
+	var got temporal.OrderStatusView
+	s.Require().NoError(val.Get(&got))
+	last := got.History[len(got.History)-2:]
+	s.Equal(temporal.ReturnRequested, last[0].To)
+	s.Equal("customer", last[0].Actor)
+	s.Equal(temporal.Refunded, last[1].To)
+	s.Equal("warehouse", last[1].Actor)
+	s.Equal(2*24*time.Hour, got.StageDurations[temporal.ReturnRequested])
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_ReturnFailed() {
+	product := uuid.New()
+	order := temporal.Order{
+		ID:        uuid.New(),
+		LineItems: []temporal.LineItem{{ProductID: product, Quantity: 1, PricePerItem: decimal.RequireFromString("10.00")}},
+	}
+
+	// Mock activity implementations. The refund is declined.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, mock.Anything).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, mock.Anything).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, mock.Anything, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, mock.Anything, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, mock.Anything, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity(s.activities.Process, mock.Anything, mock.Anything, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.ProcessingResult{}, nil)
+	s.env.OnActivity(s.activities.CommitInventory, mock.Anything, order.ID, []temporal.Allocation(nil)).Return(nil)
+	s.env.OnActivity(s.activities.CapturePayment, mock.Anything, order.ID, mock.Anything).Return(nil)
+	s.env.OnActivity(s.activities.RestockInventory, mock.Anything, order.ID, mock.Anything, []temporal.Allocation(nil)).Return(nil).Once()
+	s.env.OnActivity(s.activities.RefundPayment, mock.Anything, order.ID, mock.Anything).
+		Return(sdktemporal.NewNonRetryableApplicationError("payment conflict", temporal.PaymentConflictErrorType, nil)).
+		Once()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("pickOrder")
+	}, time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("shipOrder")
+	}, time.Hour)
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("markOrderAsDelivered")
+	}, 2*time.Hour)
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("requestReturn", temporal.ReturnRequest{
+			Actor: "customer",
+			Items: []temporal.ShipmentItem{{ProductID: product, Quantity: 1}},
+		})
+	}, 24*time.Hour)
+
+	var receiveErr error
+	s.env.RegisterDelayedCallback(func() {
+		s.env.UpdateWorkflow("receiveReturn", uuid.NewString(), &testsuite.TestUpdateCallback{
+			OnReject: func(err error) { s.Fail("update should not be rejected", err) },
+			OnAccept: func() {},
+			OnComplete: func(_ interface{}, err error) {
+				receiveErr = err
+			},
+		}, temporal.TransitionRequest{Actor: "warehouse"})
+	}, 48*time.Hour)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: order, ReturnWindow: 7 * 24 * time.Hour})
+
+	// Assert the order ended RETURN_FAILED and both the update and the
+	// workflow reported the failure.
+
+	var appErr *sdktemporal.ApplicationError
+	s.Require().ErrorAs(s.env.GetWorkflowError(), &appErr)
+	s.Equal(temporal.ReturnFailedErrorType, appErr.Type())
+	s.ErrorContains(appErr, "failed to refund returned items")
+
+	var result temporal.Result
+	s.Require().NoError(appErr.Details(&result))
+	s.Equal(temporal.ReturnFailed, result.Status)
+	s.Require().NotNil(result.Return)
+
+	s.Require().ErrorAs(receiveErr, &appErr)
+	s.Equal(temporal.ReturnFailedErrorType, appErr.Type(), "receive update should report the failure")
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err)
+	var got temporal.OrderStatusView
+	s.Require().NoError(val.Get(&got))
+	s.Equal(temporal.ReturnFailed, got.Status)
+	last := got.History[len(got.History)-1]
+	s.Equal(temporal.ReturnRequested, last.From)
+	s.Equal("returnFailure", last.Trigger)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_ReturnWindowExpired() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(nil)
+	s.env.OnActivity(s.activities.Process, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.ProcessingResult{}, nil)
+	s.env.OnActivity(s.activities.CommitInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil)
+	s.env.OnActivity(s.activities.CapturePayment, mock.Anything, uuid.UUID{}, mock.Anything).Return(nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("pickOrder")
+	}, time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("shipOrder")
+	}, time.Hour)
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("markOrderAsDelivered")
+	}, 2*time.Hour)
+
+	// Execute workflow without a return being requested.
+
+	start := s.env.Now()
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: temporal.Order{}, ReturnWindow: 7 * 24 * time.Hour})
+
+	// Assert the order completed once the window closed.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+
+	var result temporal.Result
+	s.Require().NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(temporal.Completed, result.Status)
+	s.Nil(result.Return)
+	s.Equal(2*time.Hour+7*24*time.Hour, s.env.Now().Sub(start), "workflow should stay open for the return window")
+}
+
+// insufficientInventory is the error Validate returns for products that are
+// out of stock.
+func insufficientInventory(products ...uuid.UUID) error {
+	return sdktemporal.NewNonRetryableApplicationError(
+		"insufficient inventory for products",
+		temporal.InsufficientInventoryErrorType,
+		nil,
+		products,
+	)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_BackorderWaitsForRestock() {
+	// Mock activity implementations.
+
+	product := uuid.New()
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, insufficientInventory(product)).Once()
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil).Once()
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(nil)
+	s.env.OnActivity(s.activities.ReleaseInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil)
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, uuid.UUID{}).Return(nil)
+
+	var backordered temporal.OrderStatusView
+	s.env.RegisterDelayedCallback(func() {
+		val, err := s.env.QueryWorkflow("GetOrderStatus")
+		s.Require().NoError(err)
+		s.Require().NoError(val.Get(&backordered))
+	}, time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(temporal.RestockSignal, nil)
+	}, 10*time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("cancelOrder", temporal.TransitionRequest{Actor: "customer"})
+	}, time.Hour)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{
+		Order:     temporal.Order{},
+		Backorder: temporal.BackorderOptions{Policy: temporal.BackorderWait, PollInterval: 24 * time.Hour},
+	})
+
+	// Assert the order was backordered until the restock signal.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+	s.Equal(temporal.Backordered, backordered.Status)
+	s.Equal("insufficientInventory", backordered.History[0].Trigger)
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err)
+	var got temporal.OrderStatusView
+	s.Require().NoError(val.Get(&got))
+	s.Equal(temporal.Placed, got.History[1].To)
+	s.Equal(10*time.Minute, got.StageDurations[temporal.Backordered], "restock signal should end the wait")
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_BackorderWaitExpires() {
+	// Mock activity implementations.
+
+	product := uuid.New()
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, insufficientInventory(product)).Times(4)
+
+	// Execute workflow.
+
+	start := s.env.Now()
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{
+		Order: temporal.Order{},
+		Backorder: temporal.BackorderOptions{
+			Policy:       temporal.BackorderWait,
+			WaitFor:      3 * time.Hour,
+			PollInterval: time.Hour,
+		},
+	})
+
+	// Assert inventory was polled until the deadline before the order failed.
+
+	var appErr *sdktemporal.ApplicationError
+	s.Require().ErrorAs(s.env.GetWorkflowError(), &appErr)
+	s.Equal(temporal.OrderFailedErrorType, appErr.Type())
+	s.Equal(3*time.Hour, s.env.Now().Sub(start))
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err)
+	var got temporal.OrderStatusView
+	s.Require().NoError(val.Get(&got))
+	s.Equal(temporal.UnableToComplete, got.Status)
+	s.Equal(temporal.Backordered, got.History[0].To)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_BackorderCancelled() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, insufficientInventory(uuid.New())).Once()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("cancelOrder", temporal.TransitionRequest{Actor: "customer", Reason: "too slow"})
+	}, time.Minute)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{
+		Order:     temporal.Order{},
+		Backorder: temporal.BackorderOptions{Policy: temporal.BackorderWait},
+	})
+
+	// Assert the backordered order was cancelled with nothing to undo.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+
+	var result temporal.Result
+	s.Require().NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(temporal.Cancelled, result.Status)
+	s.Equal(temporal.Backordered, result.Cancellation.Stage)
+	s.Empty(result.Compensation.Compensated)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_BackorderSplit() {
+	// Mock activity and child workflow implementations.
+
+	inStock, outOfStock := uuid.New(), uuid.New()
+	order := temporal.Order{
+		ID:         uuid.New(),
+		CustomerID: "customer-1",
+		Currency:   "AUD",
+		LineItems: []temporal.LineItem{
+			{ProductID: inStock, Quantity: 1},
+			{ProductID: outOfStock, Quantity: 2},
+		},
+	}
+	onlyInStock := mock.MatchedBy(func(o temporal.Order) bool {
+		return len(o.LineItems) == 1 && o.LineItems[0].ProductID == inStock
+	})
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, mock.Anything).Return(nil, insufficientInventory(outOfStock)).Once()
+	s.env.OnActivity(s.activities.Validate, mock.Anything, onlyInStock).Return(nil, nil).Once()
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, onlyInStock).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, onlyInStock, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, onlyInStock, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, onlyInStock, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, onlyInStock, []temporal.Allocation(nil), mock.Anything).Return(nil)
+	s.env.OnActivity(s.activities.ReleaseInventory, mock.Anything, order.ID, []temporal.Allocation(nil)).Return(nil)
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, order.ID).Return(nil)
+
+	// Mocking the workflow mocks the order under test too, so it runs the
+	// real implementation and only the backorder is mocked.
+	withPolicy := func(policy temporal.BackorderPolicy) interface{} {
+		return mock.MatchedBy(func(p temporal.Params) bool { return p.Backorder.Policy == policy })
+	}
+	s.env.OnWorkflow(temporal.ProccessOrder, mock.Anything, withPolicy(temporal.BackorderSplit)).Return(temporal.ProccessOrder).Once()
+	var backorder temporal.Params
+	s.env.OnWorkflow(temporal.ProccessOrder, mock.Anything, withPolicy(temporal.BackorderWait)).
+		Run(func(args mock.Arguments) {
+			backorder = args.Get(1).(temporal.Params)
+		}).
+		Return(temporal.Result{Status: temporal.Backordered}, nil).
+		Once()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("cancelOrder", temporal.TransitionRequest{Actor: "customer"})
+	}, time.Hour)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{
+		Order:     order,
+		Backorder: temporal.BackorderOptions{Policy: temporal.BackorderSplit, WaitFor: 48 * time.Hour},
+	})
+
+	// Assert the out of stock item was split into a backorder that waits.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+
+	s.Equal(temporal.BackorderWait, backorder.Backorder.Policy)
+	s.Equal(48*time.Hour, backorder.Backorder.WaitFor)
+	s.NotEqual(order.ID, backorder.Order.ID)
+	s.Equal(order.CustomerID, backorder.Order.CustomerID)
+	s.Equal(order.Currency, backorder.Order.Currency)
+	s.Require().Len(backorder.Order.LineItems, 1)
+	s.Equal(outOfStock, backorder.Order.LineItems[0].ProductID)
+
+	var result temporal.Result
+	s.Require().NoError(s.env.GetWorkflowResult(&result))
+	s.Require().NotNil(result.Backorder)
+	s.Equal(backorder.Order.ID, result.Backorder.OrderID)
+	s.Equal("order-"+backorder.Order.ID.String(), result.Backorder.WorkflowID)
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err)
+	var got temporal.OrderStatusView
+	s.Require().NoError(val.Get(&got))
+	s.Require().Len(got.Fulfilment, 1, "only the item in stock should be fulfilled")
+	s.Equal(inStock, got.Fulfilment[0].ProductID)
+	s.Equal(result.Backorder, got.Backorder)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_BackorderSplitRunsShortAgain() {
+	// Mock activity and child workflow implementations. The item left after
+	// the split has run out by the time the order is validated again.
+
+	inStock, outOfStock := uuid.New(), uuid.New()
+	order := temporal.Order{
+		ID: uuid.New(),
+		LineItems: []temporal.LineItem{
+			{ProductID: inStock, Quantity: 1},
+			{ProductID: outOfStock, Quantity: 2},
+		},
+	}
+	onlyInStock := mock.MatchedBy(func(o temporal.Order) bool {
+		return len(o.LineItems) == 1 && o.LineItems[0].ProductID == inStock
+	})
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, mock.Anything).Return(nil, insufficientInventory(outOfStock)).Once()
+	s.env.OnActivity(s.activities.Validate, mock.Anything, onlyInStock).Return(nil, insufficientInventory(inStock)).Once()
+	s.env.OnActivity(s.activities.Validate, mock.Anything, onlyInStock).Return(nil, nil).Once()
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, onlyInStock).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, onlyInStock, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, onlyInStock, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, onlyInStock, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, onlyInStock, []temporal.Allocation(nil), mock.Anything).Return(nil)
+	s.env.OnActivity(s.activities.ReleaseInventory, mock.Anything, order.ID, []temporal.Allocation(nil)).Return(nil)
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, order.ID).Return(nil)
+
+	withPolicy := func(policy temporal.BackorderPolicy) interface{} {
+		return mock.MatchedBy(func(p temporal.Params) bool { return p.Backorder.Policy == policy })
+	}
+	s.env.OnWorkflow(temporal.ProccessOrder, mock.Anything, withPolicy(temporal.BackorderSplit)).Return(temporal.ProccessOrder).Once()
+	s.env.OnWorkflow(temporal.ProccessOrder, mock.Anything, withPolicy(temporal.BackorderWait)).
+		Return(temporal.Result{Status: temporal.Backordered}, nil).
+		Once()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(temporal.RestockSignal, nil)
+	}, 10*time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("cancelOrder", temporal.TransitionRequest{Actor: "customer"})
+	}, time.Hour)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{
+		Order:     order,
+		Backorder: temporal.BackorderOptions{Policy: temporal.BackorderSplit, PollInterval: 24 * time.Hour},
+	})
+
+	// Assert the order waited for the item left after the split.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+
+	var result temporal.Result
+	s.Require().NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(temporal.Cancelled, result.Status)
+	s.Require().NotNil(result.Backorder)
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err)
+	var got temporal.OrderStatusView
+	s.Require().NoError(val.Get(&got))
+	s.Require().Len(got.History, 3)
+	s.Equal(temporal.Backordered, got.History[0].To)
+	s.Equal(temporal.Placed, got.History[1].To)
+	s.Equal(10*time.Minute, got.StageDurations[temporal.Backordered])
+}
+
+// reservationShortfall is the error ReserveInventory returns when stock ran
+// out after the order was validated.
+func reservationShortfall() error {
+	return sdktemporal.NewNonRetryableApplicationError(
+		"insufficient inventory to reserve order",
+		temporal.InsufficientInventoryErrorType,
+		errors.New("insufficient inventory to reserve order"),
+	)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_ReservationShortfallWaits() {
+	// Mock activity implementations. The inventory checks do not see the
+	// shortfall, so the order waits for the next poll before it is placed
+	// again; it is not screened again.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil).Times(3)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil).Twice()
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil).Twice()
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil).Once()
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil).Twice()
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(reservationShortfall()).Once()
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(nil).Once()
+	s.env.OnActivity(s.activities.ReleaseInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil).Twice()
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, uuid.UUID{}).Return(nil).Twice()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("cancelOrder", temporal.TransitionRequest{Actor: "customer"})
+	}, 2*time.Hour)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{
+		Order:     temporal.Order{},
+		Backorder: temporal.BackorderOptions{Policy: temporal.BackorderWait, PollInterval: time.Hour},
+	})
+
+	// Assert the order was backordered until the next poll, then placed.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err)
+	var got temporal.OrderStatusView
+	s.Require().NoError(val.Get(&got))
+	s.Require().Len(got.History, 3)
+	s.Equal(temporal.Backordered, got.History[0].To)
+	s.Contains(got.History[0].Reason, "insufficient inventory to reserve order")
+	s.Equal(temporal.Placed, got.History[1].To)
+	s.Equal(time.Hour, got.StageDurations[temporal.Backordered])
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_BackorderedAgainWaitsForRestock() {
+	// Mock activity implementations. The order is backordered, placed after
+	// a restock, then backordered again when its stock runs out before it is
+	// reserved.
+
+	product := uuid.New()
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, insufficientInventory(product)).Once()
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil).Once()
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, insufficientInventory(product)).Once()
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil).Once()
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil).Twice()
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil).Twice()
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil).Once()
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil).Twice()
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(reservationShortfall()).Once()
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(nil).Once()
+	s.env.OnActivity(s.activities.ReleaseInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil).Twice()
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, uuid.UUID{}).Return(nil).Twice()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(temporal.RestockSignal, nil)
+	}, 10*time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(temporal.RestockSignal, nil)
+	}, 30*time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("cancelOrder", temporal.TransitionRequest{Actor: "customer"})
+	}, time.Hour)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{
+		Order:     temporal.Order{},
+		Backorder: temporal.BackorderOptions{Policy: temporal.BackorderWait, PollInterval: 24 * time.Hour},
+	})
+
+	// Assert each restock signal ended a wait.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err)
+	var got temporal.OrderStatusView
+	s.Require().NoError(val.Get(&got))
+	s.Require().Len(got.History, 4)
+	s.Equal(temporal.Backordered, got.History[0].To)
+	s.Equal(temporal.Backordered, got.History[1].To)
+	s.Equal(temporal.Placed, got.History[2].To)
+	s.Equal(30*time.Minute, got.StageDurations[temporal.Backordered], "the second restock signal should end the second wait")
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_ReservationShortfallSplits() {
+	// Mock activity and child workflow implementations. Validating the order
+	// again finds the product that ran out, which is split off.
+
+	inStock, soldOut := uuid.New(), uuid.New()
+	order := temporal.Order{
+		ID: uuid.New(),
+		LineItems: []temporal.LineItem{
+			{ProductID: inStock, Quantity: 1},
+			{ProductID: soldOut, Quantity: 2},
+		},
+	}
+	wholeOrder := mock.MatchedBy(func(o temporal.Order) bool { return len(o.LineItems) == 2 })
+	onlyInStock := mock.MatchedBy(func(o temporal.Order) bool {
+		return len(o.LineItems) == 1 && o.LineItems[0].ProductID == inStock
+	})
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, wholeOrder).Return(nil, nil).Once()
+	s.env.OnActivity(s.activities.Validate, mock.Anything, wholeOrder).Return(nil, insufficientInventory(soldOut)).Once()
+	s.env.OnActivity(s.activities.Validate, mock.Anything, onlyInStock).Return(nil, nil).Once()
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, mock.Anything).Return(temporal.PriceBreakdown{}, nil).Twice()
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, mock.Anything, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil).Twice()
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, wholeOrder, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil).Once()
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, wholeOrder, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil).Once()
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, onlyInStock, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil).Once()
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, wholeOrder, []temporal.Allocation(nil), mock.Anything).Return(reservationShortfall()).Once()
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, onlyInStock, []temporal.Allocation(nil), mock.Anything).Return(nil).Once()
+	s.env.OnActivity(s.activities.ReleaseInventory, mock.Anything, order.ID, []temporal.Allocation(nil)).Return(nil).Twice()
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, order.ID).Return(nil).Twice()
+
+	withPolicy := func(policy temporal.BackorderPolicy) interface{} {
+		return mock.MatchedBy(func(p temporal.Params) bool { return p.Backorder.Policy == policy })
+	}
+	s.env.OnWorkflow(temporal.ProccessOrder, mock.Anything, withPolicy(temporal.BackorderSplit)).Return(temporal.ProccessOrder).Once()
+	var backorder temporal.Params
+	s.env.OnWorkflow(temporal.ProccessOrder, mock.Anything, withPolicy(temporal.BackorderWait)).
+		Run(func(args mock.Arguments) {
+			backorder = args.Get(1).(temporal.Params)
+		}).
+		Return(temporal.Result{Status: temporal.Backordered}, nil).
+		Once()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("cancelOrder", temporal.TransitionRequest{Actor: "customer"})
+	}, time.Hour)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{
+		Order:     order,
+		Backorder: temporal.BackorderOptions{Policy: temporal.BackorderSplit},
+	})
+
+	// Assert the sold out item was split off and the rest placed again.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+	s.Require().Len(backorder.Order.LineItems, 1)
+	s.Equal(soldOut, backorder.Order.LineItems[0].ProductID)
+
+	var result temporal.Result
+	s.Require().NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(temporal.Cancelled, result.Status)
+	s.Equal(temporal.Placed, result.Cancellation.Stage)
+	s.Require().NotNil(result.Backorder)
+	s.Equal(backorder.Order.ID, result.Backorder.OrderID)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_ReservationShortfallRejected() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil).Once()
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.FraudAssessment{Decision: temporal.FraudAccept}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil).Once()
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(reservationShortfall()).Once()
+	s.env.OnActivity(s.activities.ReleaseInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil).Once()
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, uuid.UUID{}).Return(nil).Once()
+
+	// Execute workflow without a backorder policy.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: temporal.Order{}})
+
+	// Assert the order failed without waiting.
+
+	var appErr *sdktemporal.ApplicationError
+	s.Require().ErrorAs(s.env.GetWorkflowError(), &appErr)
+	s.Equal(temporal.OrderFailedErrorType, appErr.Type())
+	s.ErrorContains(appErr, "insufficient inventory to reserve order")
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_FraudRejected() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).
+		Return(temporal.FraudAssessment{Decision: temporal.FraudReject, Score: 90, Reasons: []string{"order value over 5000"}}, nil)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: temporal.Order{}})
+
+	// Assert the order failed before payment was authorized.
+
+	var appErr *sdktemporal.ApplicationError
+	s.Require().ErrorAs(s.env.GetWorkflowError(), &appErr)
+	s.Equal(temporal.OrderFailedErrorType, appErr.Type())
+	s.ErrorContains(appErr, "order rejected by fraud screening: order value over 5000")
+
+	var result temporal.Result
+	s.Require().NoError(appErr.Details(&result))
+	s.Equal(temporal.UnableToComplete, result.Status)
+	s.Empty(result.Compensation.Compensated)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_FraudReviewApproved() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).
+		Return(temporal.FraudAssessment{Decision: temporal.FraudReview, Score: 40, Reasons: []string{"billing and shipping countries differ"}}, nil)
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).Return(temporal.PaymentAuthorization{Approved: true}, nil)
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).Return(nil)
+	s.env.OnActivity(s.activities.ReleaseInventory, mock.Anything, uuid.UUID{}, []temporal.Allocation(nil)).Return(nil)
+	s.env.OnActivity(s.activities.VoidPayment, mock.Anything, uuid.UUID{}).Return(nil)
+
+	// The order is held until a reviewer approves it, then placed.
+	var held temporal.OrderStatusView
+	s.env.RegisterDelayedCallback(func() {
+		val, err := s.env.QueryWorkflow("GetOrderStatus")
+		s.Require().NoError(err)
+		s.Require().NoError(val.Get(&held))
+
+		s.env.SignalWorkflow(temporal.ApproveOrderSignal, temporal.TransitionRequest{Actor: "risk-team", Reason: "customer verified"})
+	}, time.Hour)
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("cancelOrder", temporal.TransitionRequest{Actor: "customer"})
+	}, 2*time.Hour)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: temporal.Order{}})
+
+	// Assert the order was held, then placed once approved.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+	s.Equal(temporal.OnHold, held.Status)
+	s.Require().NotEmpty(held.History)
+	s.Equal("fraudReview", held.History[0].Trigger)
+	s.Equal("billing and shipping countries differ", held.History[0].Reason)
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err)
+	var got temporal.OrderStatusView
+	s.Require().NoError(val.Get(&got))
+	s.Require().Len(got.History, 3)
+	s.Equal(temporal.Placed, got.History[1].To)
+	s.Require().NotNil(got.Fraud, "fraud screening should be exposed")
+	s.Equal(temporal.FraudAccept, got.Fraud.Decision)
+	s.Require().NotNil(got.Fraud.Review)
+	s.Equal("risk-team", got.Fraud.Review.Actor)
+	s.Equal("customer verified", got.Fraud.Review.Reason)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_FraudReviewRejected() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).
+		Return(temporal.FraudAssessment{Decision: temporal.FraudReview, Score: 40}, nil)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(temporal.RejectOrderSignal, temporal.TransitionRequest{Actor: "risk-team", Reason: "stolen card"})
+	}, time.Hour)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: temporal.Order{}})
+
+	// Assert the held order failed with the review recorded.
+
+	var appErr *sdktemporal.ApplicationError
+	s.Require().ErrorAs(s.env.GetWorkflowError(), &appErr)
+	s.Equal(temporal.OrderFailedErrorType, appErr.Type())
+	s.ErrorContains(appErr, "order rejected on review by risk-team: stolen card")
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err)
+	var got temporal.OrderStatusView
+	s.Require().NoError(val.Get(&got))
+	s.Equal(temporal.UnableToComplete, got.Status)
+	s.Equal(temporal.OnHold, got.History[0].To)
+	s.Require().NotNil(got.Fraud)
+	s.Equal(temporal.FraudReject, got.Fraud.Decision)
+	s.Require().NotNil(got.Fraud.Review)
+	s.Equal("risk-team", got.Fraud.Review.Actor)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_FraudReviewTimedOut() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).
+		Return(temporal.FraudAssessment{Decision: temporal.FraudReview, Score: 40}, nil)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{
+		Order:              temporal.Order{},
+		FraudReviewTimeout: 2 * time.Hour,
+	})
+
+	// Assert the order was rejected once nobody reviewed it in time.
+
+	var appErr *sdktemporal.ApplicationError
+	s.Require().ErrorAs(s.env.GetWorkflowError(), &appErr)
+	s.Equal(temporal.OrderFailedErrorType, appErr.Type())
+	s.ErrorContains(appErr, "no review within 2h0m0s")
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err)
+	var got temporal.OrderStatusView
+	s.Require().NoError(val.Get(&got))
+	s.Equal(temporal.UnableToComplete, got.Status)
+	s.Require().NotNil(got.Fraud)
+	s.Require().NotNil(got.Fraud.Review)
+	s.True(got.Fraud.Review.TimedOut)
+	s.Equal(temporal.FraudReject, got.Fraud.Decision)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_FraudReviewIgnoresUnheldDecisions() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).
+		After(time.Minute).
+		Return(temporal.FraudAssessment{Decision: temporal.FraudReview, Score: 40}, nil)
+
+	// An approval sent while the order is still being screened, and one
+	// without an actor once it is held, are both ignored.
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(temporal.ApproveOrderSignal, temporal.TransitionRequest{Actor: "risk-team"})
+	}, 30*time.Second)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(temporal.ApproveOrderSignal, temporal.TransitionRequest{})
+	}, time.Hour)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{
+		Order:              temporal.Order{},
+		FraudReviewTimeout: 2 * time.Hour,
+	})
+
+	// Assert the order was rejected once nobody reviewed it in time.
+
+	var appErr *sdktemporal.ApplicationError
+	s.Require().ErrorAs(s.env.GetWorkflowError(), &appErr)
+	s.ErrorContains(appErr, "no review within 2h0m0s")
+
+	val, err := s.env.QueryWorkflow("GetOrderStatus")
+	s.Require().NoError(err)
+	var got temporal.OrderStatusView
+	s.Require().NoError(val.Get(&got))
+	s.Require().NotNil(got.Fraud)
+	s.Require().NotNil(got.Fraud.Review)
+	s.True(got.Fraud.Review.TimedOut)
+	s.Equal("system", got.Fraud.Review.Actor)
+}
+
+func (s *WorkflowTestSuite) TestWorkflow_FraudReviewCancelled() {
+	// Mock activity implementations.
+
+	s.env.OnActivity(s.activities.Validate, mock.Anything, temporal.Order{}).Return(nil, nil)
+	s.env.OnActivity(s.activities.PriceOrder, mock.Anything, temporal.Order{}).Return(temporal.PriceBreakdown{}, nil)
+	s.env.OnActivity(s.activities.CalculateTax, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}).Return(temporal.TaxBreakdown{}, nil)
+	s.env.OnActivity(s.activities.ScreenOrder, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).
+		Return(temporal.FraudAssessment{Decision: temporal.FraudReview}, nil)
+	// Neither funds nor stock may be held for a cancelled order.
+	s.env.OnActivity(s.activities.AuthorizePayment, mock.Anything, temporal.Order{}, temporal.PriceBreakdown{}, mock.Anything).
+		Return(temporal.PaymentAuthorization{Approved: true}, nil).Never()
+	s.env.OnActivity(s.activities.ReserveInventory, mock.Anything, temporal.Order{}, []temporal.Allocation(nil), mock.Anything).
+		Return(nil).Never()
+
+	s.env.RegisterDelayedCallback(func() {
+		s.updateWorkflow("cancelOrder", temporal.TransitionRequest{Actor: "customer", Reason: "ordered elsewhere"})
+	}, time.Hour)
+
+	// Execute workflow.
+
+	s.env.ExecuteWorkflow(temporal.ProccessOrder, temporal.Params{Order: temporal.Order{}})
+
+	// Assert the held order was cancelled with nothing to undo.
+
+	s.Require().NoError(s.env.GetWorkflowError())
+
+	var result temporal.Result
+	s.Require().NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(temporal.Cancelled, result.Status)
+	s.Equal(temporal.OnHold, result.Cancellation.Stage)
+	s.Require().NotNil(result.Compensation)
+	s.Empty(result.Compensation.Compensated)
+	s.Empty(result.Compensation.Failed)
+	s.env.AssertActivityNotCalled(s.T(), "AuthorizePayment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	s.env.AssertActivityNotCalled(s.T(), "ReserveInventory", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
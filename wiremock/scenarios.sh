@@ -11,6 +11,12 @@ show_help() {
     echo "  success              - Enable success scenario (default)"
     echo "  intermittent         - Enable intermittent failure scenario"
     echo "  non-retryable        - Enable non-retryable failure scenario"
+    echo "  reserve-insufficient - Enable insufficient inventory for reservations"
+    echo "  rate-limited         - Enable 429 responses with Retry-After"
+    echo "  batch                - Enable the batch inventory check endpoint"
+    echo "  auth-required        - Reject inventory checks sent without credentials"
+    echo "  payment-declined     - Enable declined payment authorizations"
+    echo "  payment-timeout      - Enable payment authorizations that time out"
     echo "  reset                - Reset all scenarios to default"
     echo "  status               - Show current scenario states"
     echo "  test-success         - Test success scenario"
@@ -58,6 +64,79 @@ enable_non_retryable() {
     echo "Run './scenarios.sh reset' to restore default scenario"
 }
 
+enable_reserve_insufficient() {
+    echo "Enabling insufficient inventory for reservations..."
+    curl -X POST "${WIREMOCK_URL}/__admin/mappings" \
+        -H "Content-Type: application/json" \
+        -d @wiremock/scenarios/inventory-reserve-insufficient.json
+    echo ""
+    echo "Insufficient inventory scenario is now active"
+    echo "All reservation requests will return 409 Conflict"
+    echo "Run './scenarios.sh reset' to restore default scenario"
+}
+
+enable_rate_limited() {
+    echo "Enabling rate limited scenario..."
+    curl -X POST "${WIREMOCK_URL}/__admin/mappings" \
+        -H "Content-Type: application/json" \
+        -d @wiremock/scenarios/inventory-rate-limited.json
+    echo ""
+    curl -X POST "${WIREMOCK_URL}/__admin/mappings" \
+        -H "Content-Type: application/json" \
+        -d @wiremock/scenarios/inventory-rate-limited-recovery.json
+    echo ""
+    curl -X PUT "${WIREMOCK_URL}/__admin/scenarios/RateLimited/state" \
+        -H "Content-Type: application/json" \
+        -d '{"state": "Started"}'
+    echo ""
+    echo "Rate limited scenario is now active"
+    echo "Next request will fail with 429 and Retry-After: 5, subsequent request will succeed"
+}
+
+enable_batch() {
+    echo "Enabling the batch inventory check endpoint..."
+    curl -X POST "${WIREMOCK_URL}/__admin/mappings" \
+        -H "Content-Type: application/json" \
+        -d @wiremock/scenarios/inventory-check-batch.json
+    echo ""
+    echo "Batch inventory check is now active"
+    echo "Restart the worker so the inventory client tries the batch endpoint again"
+    echo "Run './scenarios.sh reset' to restore default scenario"
+}
+
+enable_auth_required() {
+    echo "Enabling credentials check for inventory checks..."
+    curl -X POST "${WIREMOCK_URL}/__admin/mappings" \
+        -H "Content-Type: application/json" \
+        -d @wiremock/scenarios/inventory-auth-required.json
+    echo ""
+    echo "Credentials required scenario is now active"
+    echo "Inventory checks without an Authorization header will return 401 Unauthorized"
+    echo "Run './scenarios.sh reset' to restore default scenario"
+}
+
+enable_payment_declined() {
+    echo "Enabling declined payment authorizations..."
+    curl -X POST "${WIREMOCK_URL}/__admin/mappings" \
+        -H "Content-Type: application/json" \
+        -d @wiremock/scenarios/payment-authorize-declined.json
+    echo ""
+    echo "Payment declined scenario is now active"
+    echo "All authorization requests will return 402 Payment Required"
+    echo "Run './scenarios.sh reset' to restore default scenario"
+}
+
+enable_payment_timeout() {
+    echo "Enabling payment authorization timeouts..."
+    curl -X POST "${WIREMOCK_URL}/__admin/mappings" \
+        -H "Content-Type: application/json" \
+        -d @wiremock/scenarios/payment-authorize-timeout.json
+    echo ""
+    echo "Payment timeout scenario is now active"
+    echo "All authorization requests will respond after 15 seconds, past the client timeout"
+    echo "Run './scenarios.sh reset' to restore default scenario"
+}
+
 reset_scenarios() {
     echo "Resetting all scenarios..."
     curl -X POST "${WIREMOCK_URL}/__admin/scenarios/reset"
@@ -165,6 +244,24 @@ case "${1}" in
     non-retryable)
         enable_non_retryable
         ;;
+    reserve-insufficient)
+        enable_reserve_insufficient
+        ;;
+    rate-limited)
+        enable_rate_limited
+        ;;
+    batch)
+        enable_batch
+        ;;
+    auth-required)
+        enable_auth_required
+        ;;
+    payment-declined)
+        enable_payment_declined
+        ;;
+    payment-timeout)
+        enable_payment_timeout
+        ;;
     reset)
         reset_scenarios
         ;;